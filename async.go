@@ -0,0 +1,200 @@
+package logging
+// Contains the asynchronous output channel.
+
+import (
+  "errors"
+  "io"
+  "sync"
+)
+
+// Available overflow policies for asynchronous output channels.
+const (
+  // Block the calling goroutine until the queue has room for the log entry.
+  OVERFLOW_BLOCK = iota
+  // Discard the new log entry if the queue is full.
+  OVERFLOW_DROP_NEWEST
+  // Discard the oldest queued log entry to make room for the new log entry if the queue is full.
+  OVERFLOW_DROP_OLDEST
+  // Discard the new log entry if the queue is full and its level is below the threshold level, block otherwise.
+  OVERFLOW_DROP_BELOW_LEVEL
+)
+
+// ErrClosed is returned when writing to an output channel that has already been closed.
+var ErrClosed = errors.New("logging: output channel is closed")
+
+// Used internally. A queued log entry.
+type asyncEntry struct {
+  level Level
+  data  []byte
+}
+
+
+// AsyncWriter is an output channel which writes log entries to another Writer object in the background.
+//
+// Log entries are stored in a bounded queue and written by a separate goroutine, so that log calls are not stalled
+// by slow output channels. The overflow policy determines how log entries are handled when the queue is full.
+// Call Flush to wait until all queued log entries have been written and Close to stop the background goroutine.
+// AsyncWriter is safe for concurrent use by multiple goroutines and can be shared by multiple log levels.
+type AsyncWriter struct {
+  mutex     sync.Mutex
+  cond      *sync.Cond  // signals changes of the queue state
+  writer    io.Writer
+  policy    int
+  threshold Level
+  queue     []asyncEntry  // ring buffer
+  head      int
+  count     int
+  busy      bool          // indicates whether an entry is being written
+  closed    bool
+  dropped   uint64
+  err       error         // most recent write error
+  done      chan struct{}
+}
+
+
+// NewAsyncWriter returns a new AsyncWriter object which writes to the given Writer object.
+//
+// "size" specifies the max. number of queued log entries. Supported policies: OVERFLOW_BLOCK, OVERFLOW_DROP_NEWEST,
+// OVERFLOW_DROP_OLDEST and OVERFLOW_DROP_BELOW_LEVEL. The threshold level for OVERFLOW_DROP_BELOW_LEVEL defaults
+// to WARN. The caller is responsible to close the specified Writer after the AsyncWriter has been closed.
+func NewAsyncWriter(writer io.Writer, size, policy int) *AsyncWriter {
+  if size < 1 { size = 1 }
+  if policy < OVERFLOW_BLOCK || policy > OVERFLOW_DROP_BELOW_LEVEL { policy = OVERFLOW_BLOCK }
+  w := AsyncWriter{
+    writer: writer,
+    policy: policy,
+    threshold: WARN,
+    queue: make([]asyncEntry, size),
+    done: make(chan struct{}),
+  }
+  w.cond = sync.NewCond(&w.mutex)
+  go w.run()
+  return &w
+}
+
+
+// GetThreshold returns the threshold level used by the overflow policy OVERFLOW_DROP_BELOW_LEVEL.
+func (w *AsyncWriter) GetThreshold() Level {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.threshold
+}
+
+// SetThreshold defines the threshold level used by the overflow policy OVERFLOW_DROP_BELOW_LEVEL.
+// Log entries below this level are discarded if the queue is full.
+func (w *AsyncWriter) SetThreshold(level Level) {
+  w.mutex.Lock()
+  w.threshold = level
+  w.mutex.Unlock()
+}
+
+
+// Dropped returns the number of log entries discarded because of a full queue.
+func (w *AsyncWriter) Dropped() uint64 {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.dropped
+}
+
+
+// Write queues the data with log level INFO.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+  return w.WriteLevel(INFO, p)
+}
+
+
+// WriteLevel queues the data for writing in the background.
+//
+// Depending on the overflow policy the data may be discarded or the call may block if the queue is full.
+// Returns ErrClosed if the AsyncWriter has been closed.
+func (w *AsyncWriter) WriteLevel(level Level, p []byte) (int, error) {
+  data := make([]byte, len(p))
+  copy(data, p)
+
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  for !w.closed && w.count == len(w.queue) {
+    switch {
+      case w.policy == OVERFLOW_DROP_NEWEST,
+           w.policy == OVERFLOW_DROP_BELOW_LEVEL && level < w.threshold:
+        w.dropped++
+        return len(p), nil
+      case w.policy == OVERFLOW_DROP_OLDEST:
+        w.queue[w.head] = asyncEntry{}
+        w.head = (w.head + 1) % len(w.queue)
+        w.count--
+        w.dropped++
+      default:
+        w.cond.Wait()
+    }
+  }
+  if w.closed {
+    return 0, ErrClosed
+  }
+  w.queue[(w.head + w.count) % len(w.queue)] = asyncEntry{level: level, data: data}
+  w.count++
+  w.cond.Broadcast()
+  return len(p), nil
+}
+
+
+// Flush blocks until all queued log entries have been written. Returns the most recent write error, if any.
+func (w *AsyncWriter) Flush() error {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  for w.count > 0 || w.busy {
+    w.cond.Wait()
+  }
+  err := w.err
+  w.err = nil
+  return err
+}
+
+
+// Close writes all queued log entries and stops the background goroutine. The underlying Writer is not closed.
+// Returns the most recent write error, if any.
+func (w *AsyncWriter) Close() error {
+  w.mutex.Lock()
+  if w.closed {
+    w.mutex.Unlock()
+    return ErrClosed
+  }
+  w.closed = true
+  w.cond.Broadcast()
+  w.mutex.Unlock()
+
+  <-w.done
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.err
+}
+
+
+// Used internally. Writes queued log entries until the AsyncWriter is closed and the queue is empty.
+func (w *AsyncWriter) run() {
+  defer close(w.done)
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  for {
+    for w.count == 0 && !w.closed {
+      w.cond.Wait()
+    }
+    if w.count == 0 {
+      return
+    }
+    entry := w.queue[w.head]
+    w.queue[w.head] = asyncEntry{}
+    w.head = (w.head + 1) % len(w.queue)
+    w.count--
+    w.busy = true
+    w.cond.Broadcast()
+
+    w.mutex.Unlock()
+    _, err := writeLevel(w.writer, entry.level, entry.data)
+    w.mutex.Lock()
+
+    if err != nil { w.err = err }
+    w.busy = false
+    w.cond.Broadcast()
+  }
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+  "bytes"
+  "strings"
+  "sync"
+  "testing"
+)
+
+// A Writer which blocks until the gate is opened.
+type gateWriter struct {
+  mutex sync.Mutex
+  gate  chan struct{}
+  buf   bytes.Buffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+  <-w.gate
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.buf.String()
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+  var buf bytes.Buffer
+  w := NewAsyncWriter(&buf, 4, OVERFLOW_BLOCK)
+  l := NewLogger()
+  l.SetVerbosity(LOG)
+  l.SetOutput(LOG, w)
+  l.SetOutput(ERROR, w)
+
+  for i := 0; i < 100; i++ {
+    l.Logf("entry %d\n", i)
+  }
+  if err := l.Flush(); err != nil {
+    t.Fatal(err)
+  }
+  if n := strings.Count(buf.String(), "\n"); n != 100 || w.Dropped() != 0 {
+    t.Errorf("expected 100 entries without drops, got %d entries and %d drops", n, w.Dropped())
+  }
+  if !strings.HasPrefix(buf.String(), "entry 0\nentry 1\n") || !strings.HasSuffix(buf.String(), "entry 99\n") {
+    t.Errorf("unexpected order: %q", buf.String())
+  }
+  if err := w.Close(); err != nil {
+    t.Fatal(err)
+  }
+  if _, err := w.Write([]byte("closed")); err != ErrClosed {
+    t.Errorf("expected ErrClosed, got %v", err)
+  }
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+  for _, test := range []struct {
+    policy    int
+    expected  string
+  } {
+    {OVERFLOW_DROP_NEWEST, "0 1 2 "},
+    {OVERFLOW_DROP_OLDEST, "0 4 5 "},
+    {OVERFLOW_DROP_BELOW_LEVEL, "0 1 2 E "},
+  } {
+    gw := &gateWriter{gate: make(chan struct{})}
+    w := NewAsyncWriter(gw, 2, test.policy)
+    w.SetThreshold(ERROR)
+
+    w.WriteLevel(INFO, []byte("0 "))
+    // wait until the first entry is taken by the background goroutine
+    for {
+      w.mutex.Lock()
+      busy := w.busy
+      w.mutex.Unlock()
+      if busy { break }
+    }
+    for _, s := range []string{"1 ", "2 ", "3 ", "4 ", "5 "} {
+      w.WriteLevel(INFO, []byte(s))
+    }
+    done := make(chan struct{})
+    go func() {
+      if test.policy == OVERFLOW_DROP_BELOW_LEVEL {
+        w.WriteLevel(ERROR, []byte("E "))   // blocks until the queue has room
+      }
+      close(done)
+    }()
+    close(gw.gate)
+    <-done
+    w.Close()
+
+    if gw.String() != test.expected || w.Dropped() != 3 {
+      t.Errorf("policy %d: expected %q with 3 drops, got %q with %d drops", test.policy, test.expected, gw.String(), w.Dropped())
+    }
+  }
+}
@@ -0,0 +1,191 @@
+package logging
+// Contains definitions for determining and formatting the calling function of log entries.
+
+import (
+  "fmt"
+  "path/filepath"
+  "runtime"
+  "strings"
+  "sync"
+  "sync/atomic"
+)
+
+// Available components of the caller prefix. Components can be combined, e.g. CALLER_SHORT_FUNCTION | CALLER_SHORT_FILE.
+const (
+  // Fully qualified function name and line number, e.g. "github.com/user/app/db.(*Conn).Query:42". This is the default.
+  CALLER_FUNCTION = 1 << iota
+  // Package name and function name, e.g. "db.(*Conn).Query".
+  CALLER_SHORT_FUNCTION
+  // File name and line number, e.g. "conn.go:42".
+  CALLER_SHORT_FILE
+  // Full path of the file and line number, e.g. "/src/app/db/conn.go:42".
+  CALLER_LONG_FILE
+)
+
+// Used internally. Names of functions which are skipped when determining the caller, see Helper.
+var helpers sync.Map
+
+// Used internally. Incremented whenever a helper function is marked, invalidates cached frames.
+var helpersGeneration uint32
+
+// Used internally. Maps program counters returned by runtime.Callers to *callerFrame.
+var callerFrames sync.Map
+
+// Used internally. A symbolized stack frame as used for determining the caller.
+type callerFrame struct {
+  frame       runtime.Frame
+  internal    bool    // whether the frame is skipped, see isLeadingFrame
+  generation  uint32  // value of helpersGeneration when the frame was symbolized
+}
+
+// Used internally. Import path of this package.
+var packagePath = func() string {
+  pc, _, _, _ := runtime.Caller(0)
+  return funcPackage(getFrame(pc).Function)
+}()
+
+
+// Helper marks the calling function as a logging helper function, similar to testing.T.Helper.
+//
+// Helper functions are skipped when determining the caller of log entries, so that the caller prefix shows the
+// function which called the helper. Helper can be called multiple times; only the first call has an effect.
+func Helper() {
+  pc, _, _, ok := runtime.Caller(1)
+  if !ok { return }
+  if frame := getFrame(pc); len(frame.Function) > 0 {
+    if _, loaded := helpers.LoadOrStore(frame.Function, struct{}{}); !loaded { atomic.AddUint32(&helpersGeneration, 1) }
+  }
+}
+
+
+// AddCallerSkip returns a Logger that skips additional stack frames when determining the caller of log entries.
+//
+// Use it in wrapper functions which are not marked by Helper, e.g. AddCallerSkip(1) in a function which calls
+// a log function directly. The returned Logger shares all other settings with the original Logger.
+func (l *Logger) AddCallerSkip(n int) *Logger {
+  child := l.clone()
+  child.callerSkip += n
+  if child.callerSkip < 0 { child.callerSkip = 0 }
+  return child
+}
+
+// Global logger: AddCallerSkip returns a Logger that skips additional stack frames when determining the caller of
+// log entries.
+//
+// Use it in wrapper functions which are not marked by Helper, e.g. AddCallerSkip(1) in a function which calls
+// a log function directly. The returned Logger shares all other settings with the global Logger.
+func AddCallerSkip(n int) *Logger { return Global().AddCallerSkip(n) }
+
+
+// GetCallerFormat returns the components of the caller prefix.
+func (l *Logger) GetCallerFormat() int {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.callerFormat
+}
+
+// Global logger: GetCallerFormat returns the components of the caller prefix.
+func GetCallerFormat() int { return Global().GetCallerFormat() }
+
+
+// SetCallerFormat defines the components of the caller prefix.
+//
+// Components: CALLER_FUNCTION or CALLER_SHORT_FUNCTION, and CALLER_SHORT_FILE or CALLER_LONG_FILE. Function and file
+// are separated by a space, e.g. "db.(*Conn).Query conn.go:42". The line number is added to the file if defined,
+// or to the function otherwise. Default: CALLER_FUNCTION. Invalid formats are ignored.
+func (l *Logger) SetCallerFormat(format int) {
+  if format <= 0 || format >= CALLER_LONG_FILE << 1 { return }
+  l.mutex.Lock()
+  l.callerFormat = format
+  l.mutex.Unlock()
+}
+
+// Global logger: SetCallerFormat defines the components of the caller prefix.
+//
+// Components: CALLER_FUNCTION or CALLER_SHORT_FUNCTION, and CALLER_SHORT_FILE or CALLER_LONG_FILE. Function and file
+// are separated by a space, e.g. "db.(*Conn).Query conn.go:42". The line number is added to the file if defined,
+// or to the function otherwise. Default: CALLER_FUNCTION. Invalid formats are ignored.
+func SetCallerFormat(format int) { Global().SetCallerFormat(format) }
+
+
+// Used internally. Returns the calling function of the log call.
+//
+// Frames of this package (except for tests), of functions marked by Helper and of standard packages which write to
+// a LineWriter or call an slog.Handler are skipped, as well as the number of frames defined by AddCallerSkip.
+// Frames are symbolized once per program counter, see getCallerFrame.
+func (l *Logger) getCaller() runtime.Frame {
+  var pc [8]uintptr
+  skip := l.callerSkip
+  for offset := 2; ; offset += len(pc) { // skip runtime.Callers and getCaller
+    cnt := runtime.Callers(offset, pc[:])
+    for _, p := range pc[:cnt] {
+      frame, internal := getCallerFrame(p)
+      if !internal {
+        if skip == 0 { return frame }
+        skip--
+      }
+    }
+    if cnt < len(pc) { return runtime.Frame{} }
+  }
+}
+
+
+// Used internally. Returns the frame of a program counter returned by runtime.Callers and whether it is skipped when
+// determining the caller. Symbolized frames are cached until another helper function is marked.
+func getCallerFrame(pc uintptr) (runtime.Frame, bool) {
+  generation := atomic.LoadUint32(&helpersGeneration)
+  if v, ok := callerFrames.Load(pc); ok {
+    if f := v.(*callerFrame); f.generation == generation { return f.frame, f.internal }
+  }
+  // runtime.Callers returns a program counter for every inlined frame, the first frame is the one of the counter
+  frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+  f := &callerFrame{frame: frame, internal: len(frame.Function) == 0 || isLeadingFrame(frame), generation: generation}
+  callerFrames.Store(pc, f)
+  return f.frame, f.internal
+}
+
+
+// Used internally. Returns whether the frame belongs to this package or to a helper function.
+func isInternalFrame(frame runtime.Frame) bool {
+  if funcPackage(frame.Function) == packagePath && !strings.HasSuffix(frame.File, "_test.go") { return true }
+  _, ok := helpers.Load(frame.Function)
+  return ok
+}
+
+
+// Used internally. Returns whether the frame is skipped in front of the caller of the log function. Besides frames
+// of this package and of helpers, these are frames of standard packages which log or write on behalf of their caller.
+func isLeadingFrame(frame runtime.Frame) bool {
+  if isInternalFrame(frame) { return true }
+  switch funcPackage(frame.Function) {
+    case "log", "log/slog", "fmt", "io", "bufio", "runtime": return true
+  }
+  return false
+}
+
+
+// Used internally. Returns a textual representation of the calling function in the given format.
+func formatCaller(frame runtime.Frame, format int) string {
+  var fn, file string
+  switch {
+    case format & CALLER_SHORT_FUNCTION != 0: fn = shortFunction(frame.Function)
+    case format & CALLER_FUNCTION != 0:       fn = unescapeFunction(frame.Function)
+  }
+  switch {
+    case format & CALLER_LONG_FILE != 0:      file = frame.File
+    case format & CALLER_SHORT_FILE != 0:     file = filepath.Base(frame.File)
+  }
+  switch {
+    case len(file) > 0 && len(fn) > 0:  return fmt.Sprintf("%s %s:%d", fn, file, frame.Line)
+    case len(file) > 0:                 return fmt.Sprintf("%s:%d", file, frame.Line)
+    case len(fn) > 0:                   return fmt.Sprintf("%s:%d", fn, frame.Line)
+    default:                            return fmt.Sprintf("%s:%d", unescapeFunction(frame.Function), frame.Line)
+  }
+}
+
+
+// Used internally. Returns the function name qualified by the package name instead of the import path.
+func shortFunction(name string) string {
+  return unescapeFunction(name[strings.LastIndex(name, "/")+1:])
+}
+
@@ -0,0 +1,90 @@
+package logging
+
+import (
+  "bytes"
+  "fmt"
+  "reflect"
+  "runtime"
+  "strings"
+  "sync/atomic"
+  "testing"
+)
+
+// A logging helper which is skipped when determining the caller.
+func logHelper(l *Logger, msg string) {
+  Helper()
+  l.Infoln(msg)
+}
+
+// A logging wrapper which skips its own stack frame.
+func logWrapper(l *Logger, msg string) {
+  l.AddCallerSkip(1).Infoln(msg)
+}
+
+// A function which becomes a logging helper after it has logged.
+func lateHelper(l *Logger, mark bool, msg string) {
+  if mark { Helper() }
+  l.Infoln(msg)
+}
+
+func TestCaller(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixCaller(true)
+  l.SetOutput(INFO, &buf)
+  l.SetCallerFormat(CALLER_SHORT_FILE)
+  if l.GetCallerFormat() != CALLER_SHORT_FILE {
+    t.Errorf("unexpected caller format: %d", l.GetCallerFormat())
+  }
+
+  _, file, line, _ := runtime.Caller(0)
+  l.Infoln("direct")
+  logHelper(l, "helper")
+  logWrapper(l, "wrapper")
+  expected := fmt.Sprintf("caller_test.go:%d direct\ncaller_test.go:%d helper\ncaller_test.go:%d wrapper\n", line + 1, line + 2, line + 3)
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  pc, _, _, _ := runtime.Caller(0)
+  function := getFrame(pc).Function
+  short := function[strings.LastIndex(function, "/")+1:]
+  tests := []struct {
+    format    int
+    expected  string
+  }{
+    {CALLER_FUNCTION, fmt.Sprintf("%s:%d", function, line + 1)},
+    {CALLER_SHORT_FUNCTION, fmt.Sprintf("%s:%d", short, line + 1)},
+    {CALLER_LONG_FILE, fmt.Sprintf("%s:%d", file, line + 1)},
+    {CALLER_SHORT_FUNCTION | CALLER_SHORT_FILE, fmt.Sprintf("%s caller_test.go:%d", short, line + 1)},
+  }
+  frame := getFrame(pc)
+  frame.Line = line + 1
+  for _, test := range tests {
+    if s := formatCaller(frame, test.format); s != test.expected {
+      t.Errorf("format %d: expected %q, got %q", test.format, test.expected, s)
+    }
+  }
+}
+
+
+func TestCallerCache(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixCaller(true)
+  l.SetOutput(INFO, &buf)
+  l.SetCallerFormat(CALLER_SHORT_FUNCTION)
+  defer func() {
+    // helpers cannot be unmarked, restore the state for repeated test runs
+    helpers.Delete(runtime.FuncForPC(reflect.ValueOf(lateHelper).Pointer()).Name())
+    atomic.AddUint32(&helpersGeneration, 1)
+  }()
+  lateHelper(l, false, "before")
+  lateHelper(l, false, "cached")
+  lateHelper(l, true, "after")
+  lines := strings.Split(buf.String(), "\n")
+  if !strings.Contains(lines[0], ".lateHelper:") || !strings.Contains(lines[1], ".lateHelper:") ||
+     !strings.Contains(lines[2], ".TestCallerCache:") {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
@@ -0,0 +1,136 @@
+package logging
+// Contains collapsing of repeated log messages.
+
+import (
+  "fmt"
+  "io"
+  "reflect"
+  "strings"
+  "time"
+)
+
+// Used internally. The most recent log entry written to an output channel and the number of its repetitions.
+type repeatState struct {
+  key       string      // level, name, message and fields of the log entry
+  entry     Entry       // most recent repetition
+  formatter Formatter
+  count     int         // number of held repetitions
+  timer     *time.Timer // reports held repetitions after the timeout
+}
+
+
+// GetCollapseRepeats returns the timeout for collapsing repeated log messages. Returns 0 if collapsing is disabled.
+func (l *Logger) GetCollapseRepeats() time.Duration {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.collapseTimeout
+}
+
+// Global logger: GetCollapseRepeats returns the timeout for collapsing repeated log messages.
+// Returns 0 if collapsing is disabled.
+func GetCollapseRepeats() time.Duration { return Global().GetCollapseRepeats() }
+
+
+// SetCollapseRepeats enables collapsing of repeated log messages if the timeout is greater than 0.
+//
+// Consecutive log entries of the same level with identical message and fields which are sent to the same output
+// channel are held back. A single entry "last message repeated N times" is written instead when a different log entry
+// is sent to the output channel, when the timeout has elapsed after the first repetition, or when Flush is called.
+// Output channels which cannot be compared, such as non-pointer values of slice or map types, are not collapsed.
+// Specify 0 to disable collapsing. Held repetitions are reported before collapsing is disabled.
+func (l *Logger) SetCollapseRepeats(timeout time.Duration) {
+  if timeout < 0 { timeout = 0 }
+  l.mutex.Lock()
+  l.collapseTimeout = timeout
+  l.mutex.Unlock()
+  if timeout == 0 { l.flushRepeats() }
+}
+
+// Global logger: SetCollapseRepeats enables collapsing of repeated log messages if the timeout is greater than 0.
+//
+// Consecutive log entries of the same level with identical message and fields which are sent to the same output
+// channel are held back. A single entry "last message repeated N times" is written instead when a different log entry
+// is sent to the output channel, when the timeout has elapsed after the first repetition, or when Flush is called.
+// Output channels which cannot be compared, such as non-pointer values of slice or map types, are not collapsed.
+// Specify 0 to disable collapsing. Held repetitions are reported before collapsing is disabled.
+func SetCollapseRepeats(timeout time.Duration) { Global().SetCollapseRepeats(timeout) }
+
+
+// Used internally. Writes the log entry unless it repeats the previous log entry of the output channel.
+// The caller must hold the write lock.
+func (l *Logger) collapse(w io.Writer, formatter Formatter, entry *Entry, timeout time.Duration) error {
+  if !reflect.TypeOf(w).Comparable() {
+    return l.retry(func() error { return writeEntry(w, formatter, entry) })
+  }
+  key := repeatKey(entry)
+  state := l.repeats[w]
+  if state != nil && state.key == key {
+    state.entry = *entry
+    state.count++
+    if state.timer == nil {
+      state.timer = time.AfterFunc(timeout, func() { l.reportRepeats(w, state) })
+    }
+    return nil
+  }
+
+  var err error
+  if state != nil { err = state.report(l, w) }
+  if err2 := l.retry(func() error { return writeEntry(w, formatter, entry) }); err == nil { err = err2 }
+  if l.repeats == nil { l.repeats = make(map[io.Writer]*repeatState) }
+  l.repeats[w] = &repeatState{key: key, entry: *entry, formatter: formatter}
+  return err
+}
+
+
+// Used internally. Writes held repetitions of all output channels.
+func (l *Logger) flushRepeats() {
+  l.writeMutex.Lock()
+  var err error
+  for w, state := range l.repeats {
+    if err2 := state.report(l, w); err == nil { err = err2 }
+    delete(l.repeats, w)
+  }
+  l.writeMutex.Unlock()
+  if err != nil { l.handleError(err, nil) }
+}
+
+
+// Used internally. Writes held repetitions of the output channel after the timeout.
+func (l *Logger) reportRepeats(w io.Writer, state *repeatState) {
+  l.writeMutex.Lock()
+  var err error
+  if l.repeats[w] == state {
+    err = state.report(l, w)
+    delete(l.repeats, w)
+  }
+  l.writeMutex.Unlock()
+  if err != nil { l.handleError(err, nil) }
+}
+
+
+// Used internally. Writes an entry which reports the number of held repetitions. The caller must hold the write lock.
+func (s *repeatState) report(l *Logger, w io.Writer) error {
+  if s.timer != nil {
+    s.timer.Stop()
+    s.timer = nil
+  }
+  if s.count == 0 { return nil }
+  entry := s.entry
+  entry.Fields = nil
+  if s.count == 1 {
+    entry.Message = "last message repeated 1 time\n"
+  } else {
+    entry.Message = fmt.Sprintf("last message repeated %d times\n", s.count)
+  }
+  s.count = 0
+  return l.retry(func() error { return writeEntry(w, s.formatter, &entry) })
+}
+
+
+// Used internally. Returns a key which identifies repetitions of the log entry.
+func repeatKey(entry *Entry) string {
+  var sb strings.Builder
+  fmt.Fprintf(&sb, "%d\x00%s\x00%s\x00", int(entry.Level), entry.Name, entry.Message)
+  writeFields(&sb, entry.Fields)
+  return sb.String()
+}
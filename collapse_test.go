@@ -0,0 +1,76 @@
+package logging
+
+import (
+  "bytes"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+  var out, errOut bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &out)
+  l.SetOutput(WARN, &out)
+  l.SetOutput(ERROR, &errOut)
+  l.SetCollapseRepeats(time.Hour)
+  if l.GetCollapseRepeats() != time.Hour {
+    t.Errorf("unexpected timeout: %v", l.GetCollapseRepeats())
+  }
+
+  for i := 0; i < 3; i++ {
+    l.Warnln("disk full")
+    l.Errorw("connection lost", "host", "db")
+  }
+  l.Infoln("disk full")             // different level
+  l.Warnw("disk full", "n", 1)      // different fields
+  l.Warnw("disk full", "n", 1)
+  l.Named("db").Warnw("disk full", "n", 1)  // different name
+  l.Errorw("connection lost", "host", "cache")
+  l.Flush()
+
+  expected := "WARN disk full\n" +
+              "WARN last message repeated 2 times\n" +
+              "INFO disk full\n" +
+              "WARN disk full n=1\n" +
+              "WARN last message repeated 1 time\n" +
+              "WARN db: disk full n=1\n"
+  if out.String() != expected {
+    t.Errorf("expected %q, got %q", expected, out.String())
+  }
+  expected = "ERRO connection lost host=db\n" +
+             "ERRO last message repeated 2 times\n" +
+             "ERRO connection lost host=cache\n"
+  if errOut.String() != expected {
+    t.Errorf("expected %q, got %q", expected, errOut.String())
+  }
+
+  // repetitions after the summary are held again
+  out.Reset()
+  l.Warnln("disk full")
+  l.Warnln("disk full")
+  l.SetCollapseRepeats(0)
+  l.Warnln("disk full")
+  if expected := "WARN disk full\nWARN last message repeated 1 time\nWARN disk full\n"; out.String() != expected {
+    t.Errorf("expected %q, got %q", expected, out.String())
+  }
+}
+
+
+func TestCollapseRepeatsTimeout(t *testing.T) {
+  var buf syncBuffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  l.SetCollapseRepeats(20 * time.Millisecond)
+  for i := 0; i < 5; i++ {
+    l.Infoln("tick")
+  }
+  for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+    if strings.Contains(buf.String(), "repeated") { break }
+    time.Sleep(5 * time.Millisecond)
+  }
+  if buf.String() != "tick\nlast message repeated 4 times\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
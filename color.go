@@ -0,0 +1,116 @@
+package logging
+// Contains definitions for colorized terminal output.
+
+import (
+  "io"
+  "os"
+  "strings"
+  "sync"
+)
+
+// Available color modes.
+const (
+  // Never use colors. This is the default mode.
+  COLOR_NEVER = iota
+  // Use colors if the output channel is a terminal. Respects the environment variables NO_COLOR, FORCE_COLOR and TERM.
+  COLOR_AUTO
+  // Always use colors.
+  COLOR_ALWAYS
+)
+
+// A set of ANSI color sequences which can be used as level colors.
+const (
+  COLOR_RESET     = "\x1b[0m"
+  COLOR_BOLD      = "\x1b[1m"
+  COLOR_DIM       = "\x1b[2m"
+  COLOR_RED       = "\x1b[31m"
+  COLOR_GREEN     = "\x1b[32m"
+  COLOR_YELLOW    = "\x1b[33m"
+  COLOR_BLUE      = "\x1b[34m"
+  COLOR_MAGENTA   = "\x1b[35m"
+  COLOR_CYAN      = "\x1b[36m"
+  COLOR_GRAY      = "\x1b[90m"
+  COLOR_BOLD_RED  = "\x1b[1;31m"
+  COLOR_ALERT     = "\x1b[1;37;41m"
+)
+
+
+// GetColorMode returns the color mode of the Logger.
+func (l *Logger) GetColorMode() int {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.colorMode
+}
+
+// Global logger: GetColorMode returns the color mode of the global Logger.
+func GetColorMode() int { return Global().GetColorMode() }
+
+
+// SetColorMode defines whether log entries are colorized by ANSI escape sequences.
+//
+// Supported modes: COLOR_NEVER, COLOR_AUTO and COLOR_ALWAYS. The TextFormatter prints the level tag in the color of
+// the log level and dims timestamp and caller. In mode COLOR_AUTO colors are used if the output channel of the log
+// level is a terminal, so output to files or pipes remains free of color sequences. The environment variables
+// NO_COLOR and TERM=dumb disable and FORCE_COLOR enables colors in mode COLOR_AUTO. They are read when the mode is
+// set. Log messages are written unchanged. Unsupported modes are ignored.
+func (l *Logger) SetColorMode(mode int) {
+  if mode < COLOR_NEVER || mode > COLOR_ALWAYS { return }
+  resolved := colorEnv(mode)
+  l.mutex.Lock()
+  l.colorMode = mode
+  l.colorResolved = resolved
+  l.terminals = new(sync.Map)
+  l.mutex.Unlock()
+}
+
+// Global logger: SetColorMode defines whether log entries are colorized by ANSI escape sequences.
+//
+// Supported modes: COLOR_NEVER, COLOR_AUTO and COLOR_ALWAYS. The TextFormatter prints the level tag in the color of
+// the log level and dims timestamp and caller. In mode COLOR_AUTO colors are used if the output channel of the log
+// level is a terminal, so output to files or pipes remains free of color sequences. The environment variables
+// NO_COLOR and TERM=dumb disable and FORCE_COLOR enables colors in mode COLOR_AUTO. They are read when the mode is
+// set. Log messages are written unchanged. Unsupported modes are ignored.
+func SetColorMode(mode int) { Global().SetColorMode(mode) }
+
+
+// Used internally. Returns the color mode which results from the environment variables in the given mode.
+// Mode COLOR_AUTO is replaced by COLOR_NEVER or COLOR_ALWAYS if the environment disables or forces colors.
+func colorEnv(mode int) int {
+  if mode != COLOR_AUTO { return mode }
+  if len(os.Getenv("NO_COLOR")) > 0 { return COLOR_NEVER }
+  if force := os.Getenv("FORCE_COLOR"); len(force) > 0 && force != "0" && !strings.EqualFold(force, "false") {
+    return COLOR_ALWAYS
+  }
+  if os.Getenv("TERM") == "dumb" { return COLOR_NEVER }
+  return COLOR_AUTO
+}
+
+
+// Used internally. Returns whether colors should be used for the given output channel in the resolved color mode.
+// The terminal detection of files is cached in the given map.
+func useColor(mode int, w io.Writer, terminals *sync.Map) bool {
+  switch mode {
+    case COLOR_ALWAYS: return true
+    case COLOR_AUTO:   return isTerminal(w, terminals)
+    default:           return false
+  }
+}
+
+
+// Used internally. Returns whether the Writer object is a terminal. Results are cached in the given map.
+func isTerminal(w io.Writer, terminals *sync.Map) bool {
+  f, ok := w.(*os.File)
+  if !ok || f == nil { return false }
+  if v, ok := terminals.Load(f); ok { return v.(bool) }
+  fi, err := f.Stat()
+  result := err == nil && fi.Mode() & os.ModeCharDevice != 0 && f.Name() != os.DevNull && f != Stdnull
+  terminals.Store(f, result)
+  return result
+}
+
+
+// Used internally. Returns the text enclosed by the given color sequence and a reset sequence.
+func colorize(color, text string) string {
+  if len(color) == 0 { return text }
+  return color + text + COLOR_RESET
+}
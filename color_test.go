@@ -0,0 +1,78 @@
+package logging
+
+import (
+  "bytes"
+  "os"
+  "sync"
+  "testing"
+)
+
+func TestColorMode(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(WARN, &buf)
+
+  l.Infoln("\x1b[1mbold\x1b[0m")
+  l.SetColorMode(COLOR_ALWAYS)
+  l.Warnln("colored")
+  expected := "INFO \x1b[1mbold\x1b[0m\n" +
+              COLOR_YELLOW + "WARN" + COLOR_RESET + " colored\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  // output to a buffer is not a terminal, messages are not modified
+  buf.Reset()
+  t.Setenv("FORCE_COLOR", "")
+  t.Setenv("NO_COLOR", "")
+  l.SetColorMode(COLOR_AUTO)
+  if l.GetColorMode() != COLOR_AUTO {
+    t.Errorf("unexpected color mode: %d", l.GetColorMode())
+  }
+  l.Infoln("\x1b[1mkept\x1b[0m")
+  if buf.String() != "INFO \x1b[1mkept\x1b[0m\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+
+  // environment variables are read when the mode is set
+  buf.Reset()
+  t.Setenv("FORCE_COLOR", "1")
+  l.SetColorMode(COLOR_AUTO)
+  if err := SetLevelColor(INFO, COLOR_MAGENTA); err != nil {
+    t.Fatal(err)
+  }
+  defer SetLevelColor(INFO, COLOR_GREEN)
+  l.With(PrefixOptions{Timestamp: true, Level: true}).Infoln("forced")
+  ts := buf.String()[len(COLOR_DIM):len(COLOR_DIM) + len(TS_FMT_TIME_MILLI)]
+  if expected := COLOR_DIM + ts + COLOR_RESET + " " + COLOR_MAGENTA + "INFO" + COLOR_RESET + " forced\n"; buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  buf.Reset()
+  t.Setenv("NO_COLOR", "1")
+  l.Infoln("forced")
+  l.SetColorMode(COLOR_AUTO)
+  l.Infoln("plain")
+  if buf.String() != COLOR_MAGENTA + "INFO" + COLOR_RESET + " forced\nINFO plain\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+  t.Setenv("NO_COLOR", "")
+  t.Setenv("FORCE_COLOR", "")
+  t.Setenv("TERM", "dumb")
+  if colorEnv(COLOR_AUTO) != COLOR_NEVER {
+    t.Error("colors not disabled by TERM")
+  }
+  if SetLevelColor(Level(33), COLOR_RED) == nil {
+    t.Error("expected error for unregistered level")
+  }
+  terminals := new(sync.Map)
+  if isTerminal(Stdnull, terminals) || isTerminal(&buf, terminals) {
+    t.Error("unexpected terminal detection")
+  }
+  if f, err := os.CreateTemp(t.TempDir(), "log"); err == nil {
+    if isTerminal(f, terminals) { t.Error("file detected as terminal") }
+    f.Close()
+  }
+}
@@ -0,0 +1,372 @@
+package logging
+// Contains the context.Context-aware logging API.
+
+import (
+  "context"
+  "sync"
+)
+
+// ContextExtractor returns fields extracted from a context, such as request IDs or trace and span IDs.
+// It is called for every log entry with a context that passes verbosity filtering. It should return nil if the
+// context does not contain relevant values.
+type ContextExtractor func(ctx context.Context) Fields
+
+// Used internally. Key types of values stored in a context.
+type (
+  loggerKey struct{}
+  fieldsKey struct{}
+)
+
+// Used internally. A registered context extractor. Registrations are identified by their address.
+type extractorEntry struct {
+  fn ContextExtractor
+}
+
+// Used internally. The registered context extractors. Lists are never modified after they have been assigned.
+var extractors = struct {
+  mutex sync.RWMutex
+  funcs []*extractorEntry
+}{}
+
+
+// RegisterContextExtractor adds a function which extracts fields from the context of log calls.
+//
+// Extracted fields are added to all log entries logged with a context, e.g. by InfoContext or by SlogHandler.
+// Extractors are called in the order of registration. Returns a function which unregisters the extractor. Calling
+// it more than once has no effect. Example which adds OpenTelemetry trace and span IDs:
+//
+//   logging.RegisterContextExtractor(func(ctx context.Context) logging.Fields {
+//     sc := trace.SpanContextFromContext(ctx)
+//     if !sc.IsValid() { return nil }
+//     return logging.Fields{logging.String("trace_id", sc.TraceID().String()), logging.String("span_id", sc.SpanID().String())}
+//   })
+func RegisterContextExtractor(fn ContextExtractor) (unregister func()) {
+  if fn == nil { return func() {} }
+  entry := &extractorEntry{fn: fn}
+  extractors.mutex.Lock()
+  defer extractors.mutex.Unlock()
+  funcs := make([]*extractorEntry, 0, len(extractors.funcs) + 1)
+  extractors.funcs = append(append(funcs, extractors.funcs...), entry)
+  return func() { unregisterContextExtractor(entry) }
+}
+
+
+// Used internally. Removes the registered context extractor.
+func unregisterContextExtractor(entry *extractorEntry) {
+  extractors.mutex.Lock()
+  defer extractors.mutex.Unlock()
+  funcs := make([]*extractorEntry, 0, len(extractors.funcs))
+  for _, e := range extractors.funcs {
+    if e != entry { funcs = append(funcs, e) }
+  }
+  extractors.funcs = funcs
+}
+
+
+// ContextValueExtractor returns a ContextExtractor which adds the context value of the given key as field
+// of the given name. Nothing is added if the context does not contain the value.
+func ContextValueExtractor(name string, key interface{}) ContextExtractor {
+  return func(ctx context.Context) Fields {
+    if v := ctx.Value(key); v != nil { return Fields{Field{Key: name, Value: v}} }
+    return nil
+  }
+}
+
+
+// NewContext returns a copy of the context which carries the given Logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+  return context.WithValue(ctx, loggerKey{}, l)
+}
+
+
+// FromContext returns the Logger carried by the context. Returns the global Logger if the context does not carry
+// a Logger.
+func FromContext(ctx context.Context) *Logger {
+  if ctx != nil {
+    if l, ok := ctx.Value(loggerKey{}).(*Logger); ok && l != nil { return l }
+  }
+  return Global()
+}
+
+
+// ContextWithFields returns a copy of the context which carries the given key/value pairs in addition to the fields
+// already carried by the context. The fields are added to all log entries logged with the context.
+//
+// Fields are specified as alternating keys and values or as Field objects.
+func ContextWithFields(ctx context.Context, keysAndValues ...interface{}) context.Context {
+  fields, _ := ctx.Value(fieldsKey{}).(Fields)
+  return context.WithValue(ctx, fieldsKey{}, appendFields(fields, makeFields(keysAndValues)))
+}
+
+
+// FieldsFromContext returns the fields carried by the context, followed by the fields of all registered extractors.
+func FieldsFromContext(ctx context.Context) Fields {
+  if ctx == nil { return nil }
+  fields, _ := ctx.Value(fieldsKey{}).(Fields)
+  extractors.mutex.RLock()
+  funcs := extractors.funcs
+  extractors.mutex.RUnlock()
+  for _, e := range funcs {
+    fields = appendFields(fields, e.fn(ctx))
+  }
+  return fields
+}
+
+
+// LogContext prints the message if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) LogContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, LOG, "%s", msg)
+}
+
+// Global logger: LogContext prints the message if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func LogContext(ctx context.Context, msg string) { Global().LogContext(ctx, msg) }
+
+// LogfContext prints the formatted string if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) LogfContext(ctx context.Context, format string, a ...interface{}) {
+  l.logfContext(ctx, LOG, format, a...)
+}
+
+// Global logger: LogfContext prints the formatted string if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func LogfContext(ctx context.Context, format string, a ...interface{}) { Global().LogfContext(ctx, format, a...) }
+
+// LoglnContext prints the message and a newline if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) LoglnContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, LOG, "%s\n", msg)
+}
+
+// Global logger: LoglnContext prints the message and a newline if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func LoglnContext(ctx context.Context, msg string) { Global().LoglnContext(ctx, msg) }
+
+// LogwContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) LogwContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+  l.logwContext(ctx, LOG, msg, keysAndValues)
+}
+
+// Global logger: LogwContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to LOG.
+// Fields carried by the context are added to the log entry.
+func LogwContext(ctx context.Context, msg string, keysAndValues ...interface{}) { Global().LogwContext(ctx, msg, keysAndValues...) }
+
+// InfoContext prints the message if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) InfoContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, INFO, "%s", msg)
+}
+
+// Global logger: InfoContext prints the message if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func InfoContext(ctx context.Context, msg string) { Global().InfoContext(ctx, msg) }
+
+// InfofContext prints the formatted string if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) InfofContext(ctx context.Context, format string, a ...interface{}) {
+  l.logfContext(ctx, INFO, format, a...)
+}
+
+// Global logger: InfofContext prints the formatted string if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func InfofContext(ctx context.Context, format string, a ...interface{}) { Global().InfofContext(ctx, format, a...) }
+
+// InfolnContext prints the message and a newline if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) InfolnContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, INFO, "%s\n", msg)
+}
+
+// Global logger: InfolnContext prints the message and a newline if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func InfolnContext(ctx context.Context, msg string) { Global().InfolnContext(ctx, msg) }
+
+// InfowContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) InfowContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+  l.logwContext(ctx, INFO, msg, keysAndValues)
+}
+
+// Global logger: InfowContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to INFO or lower.
+// Fields carried by the context are added to the log entry.
+func InfowContext(ctx context.Context, msg string, keysAndValues ...interface{}) { Global().InfowContext(ctx, msg, keysAndValues...) }
+
+// WarnContext prints the message if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) WarnContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, WARN, "%s", msg)
+}
+
+// Global logger: WarnContext prints the message if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func WarnContext(ctx context.Context, msg string) { Global().WarnContext(ctx, msg) }
+
+// WarnfContext prints the formatted string if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) WarnfContext(ctx context.Context, format string, a ...interface{}) {
+  l.logfContext(ctx, WARN, format, a...)
+}
+
+// Global logger: WarnfContext prints the formatted string if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func WarnfContext(ctx context.Context, format string, a ...interface{}) { Global().WarnfContext(ctx, format, a...) }
+
+// WarnlnContext prints the message and a newline if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) WarnlnContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, WARN, "%s\n", msg)
+}
+
+// Global logger: WarnlnContext prints the message and a newline if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func WarnlnContext(ctx context.Context, msg string) { Global().WarnlnContext(ctx, msg) }
+
+// WarnwContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) WarnwContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+  l.logwContext(ctx, WARN, msg, keysAndValues)
+}
+
+// Global logger: WarnwContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to WARN or lower.
+// Fields carried by the context are added to the log entry.
+func WarnwContext(ctx context.Context, msg string, keysAndValues ...interface{}) { Global().WarnwContext(ctx, msg, keysAndValues...) }
+
+// ErrorContext prints the message if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) ErrorContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, ERROR, "%s", msg)
+}
+
+// Global logger: ErrorContext prints the message if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func ErrorContext(ctx context.Context, msg string) { Global().ErrorContext(ctx, msg) }
+
+// ErrorfContext prints the formatted string if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) ErrorfContext(ctx context.Context, format string, a ...interface{}) {
+  l.logfContext(ctx, ERROR, format, a...)
+}
+
+// Global logger: ErrorfContext prints the formatted string if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func ErrorfContext(ctx context.Context, format string, a ...interface{}) { Global().ErrorfContext(ctx, format, a...) }
+
+// ErrorlnContext prints the message and a newline if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) ErrorlnContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, ERROR, "%s\n", msg)
+}
+
+// Global logger: ErrorlnContext prints the message and a newline if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func ErrorlnContext(ctx context.Context, msg string) { Global().ErrorlnContext(ctx, msg) }
+
+// ErrorwContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) ErrorwContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+  l.logwContext(ctx, ERROR, msg, keysAndValues)
+}
+
+// Global logger: ErrorwContext prints the message followed by the given key/value pairs and a newline if current verbosity is set to ERROR or lower.
+// Fields carried by the context are added to the log entry.
+func ErrorwContext(ctx context.Context, msg string, keysAndValues ...interface{}) { Global().ErrorwContext(ctx, msg, keysAndValues...) }
+
+// CriticalContext prints the message and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) CriticalContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, CRITICAL, "%s", msg)
+}
+
+// Global logger: CriticalContext prints the message and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func CriticalContext(ctx context.Context, msg string) { Global().CriticalContext(ctx, msg) }
+
+// CriticalfContext prints the formatted string and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) CriticalfContext(ctx context.Context, format string, a ...interface{}) {
+  l.logfContext(ctx, CRITICAL, format, a...)
+}
+
+// Global logger: CriticalfContext prints the formatted string and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func CriticalfContext(ctx context.Context, format string, a ...interface{}) { Global().CriticalfContext(ctx, format, a...) }
+
+// CriticallnContext prints the message and a newline and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) CriticallnContext(ctx context.Context, msg string) {
+  l.logfContext(ctx, CRITICAL, "%s\n", msg)
+}
+
+// Global logger: CriticallnContext prints the message and a newline and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func CriticallnContext(ctx context.Context, msg string) { Global().CriticallnContext(ctx, msg) }
+
+// CriticalwContext prints the message followed by the given key/value pairs and a newline and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) CriticalwContext(ctx context.Context, msg string, keysAndValues ...interface{}) {
+  l.logwContext(ctx, CRITICAL, msg, keysAndValues)
+}
+
+// Global logger: CriticalwContext prints the message followed by the given key/value pairs and a newline and performs the critical action.
+// Fields carried by the context are added to the log entry.
+func CriticalwContext(ctx context.Context, msg string, keysAndValues ...interface{}) { Global().CriticalwContext(ctx, msg, keysAndValues...) }
+
+// PrintContext prints the message if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) PrintContext(ctx context.Context, level Level, msg string) {
+  l.logfContext(ctx, level, "%s", msg)
+}
+
+// Global logger: PrintContext prints the message if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func PrintContext(ctx context.Context, level Level, msg string) { Global().PrintContext(ctx, level, msg) }
+
+// PrintfContext prints the formatted string if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) PrintfContext(ctx context.Context, level Level, format string, a ...interface{}) {
+  l.logfContext(ctx, level, format, a...)
+}
+
+// Global logger: PrintfContext prints the formatted string if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func PrintfContext(ctx context.Context, level Level, format string, a ...interface{}) { Global().PrintfContext(ctx, level, format, a...) }
+
+// PrintlnContext prints the message and a newline if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) PrintlnContext(ctx context.Context, level Level, msg string) {
+  l.logfContext(ctx, level, "%s\n", msg)
+}
+
+// Global logger: PrintlnContext prints the message and a newline if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func PrintlnContext(ctx context.Context, level Level, msg string) { Global().PrintlnContext(ctx, level, msg) }
+
+// PrintwContext prints the message followed by the given key/value pairs and a newline if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func (l *Logger) PrintwContext(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) {
+  l.logwContext(ctx, level, msg, keysAndValues)
+}
+
+// Global logger: PrintwContext prints the message followed by the given key/value pairs and a newline if current verbosity level is set to the given level or lower.
+// Fields carried by the context are added to the log entry.
+func PrintwContext(ctx context.Context, level Level, msg string, keysAndValues ...interface{}) { Global().PrintwContext(ctx, level, msg, keysAndValues...) }
+
+
+// Used internally. Handles writing log messages with a context. Fields are extracted from the context only if
+// the log entry passes verbosity filtering or a critical action is performed for it.
+func (l *Logger) logfContext(ctx context.Context, level Level, format string, a ...interface{}) {
+  caller, enabled := l.filterEntry(level)
+  if !enabled && level < CRITICAL { return }
+  l.logFiltered(level, caller, enabled, appendFields(l.fields, FieldsFromContext(ctx)), format, a...)
+}
+
+
+// Used internally. Handles writing log messages with a context and structured fields.
+func (l *Logger) logwContext(ctx context.Context, level Level, msg string, keysAndValues []interface{}) {
+  caller, enabled := l.filterEntry(level)
+  if !enabled && level < CRITICAL { return }
+  fields := appendFields(appendFields(l.fields, FieldsFromContext(ctx)), makeFields(keysAndValues))
+  l.logFiltered(level, caller, enabled, fields, "%s\n", msg)
+}
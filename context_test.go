@@ -0,0 +1,62 @@
+package logging
+
+import (
+  "bytes"
+  "context"
+  "testing"
+)
+
+type requestIDKey struct{}
+
+func TestContext(t *testing.T) {
+  defer RegisterContextExtractor(ContextValueExtractor("request_id", requestIDKey{}))()
+
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(WARN, &buf)
+
+  ctx := NewContext(context.Background(), l.Named("http"))
+  ctx = ContextWithFields(ctx, "user", "alice")
+  ctx = context.WithValue(ctx, requestIDKey{}, 42)
+  ctx = ContextWithFields(ctx, String("method", "GET"))
+
+  FromContext(ctx).WithFields("bound", true).InfofContext(ctx, "%d items\n", 3)
+  FromContext(ctx).WarnwContext(ctx, "slow", "ms", 350)
+  l.LoglnContext(ctx, "filtered")
+  l.PrintContext(context.Background(), INFO, "no fields\n")
+
+  expected := "INFO http: 3 items bound=true user=alice method=GET request_id=42\n" +
+              "WARN http: slow user=alice method=GET request_id=42 ms=350\n" +
+              "INFO no fields\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+  if FromContext(context.Background()) != Global() {
+    t.Error("expected global Logger for context without Logger")
+  }
+}
+
+
+func TestContextExtractor(t *testing.T) {
+  var calls int
+  unregister := RegisterContextExtractor(func(ctx context.Context) Fields {
+    calls++
+    return Fields{Int("calls", calls)}
+  })
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  ctx := context.Background()
+
+  l.LoglnContext(ctx, "filtered")
+  l.LogwContext(ctx, "filtered")
+  l.InfolnContext(ctx, "extracted")
+  unregister()
+  unregister()
+  l.InfolnContext(ctx, "unregistered")
+  if buf.String() != "extracted calls=1\nunregistered\n" || calls != 1 {
+    t.Errorf("unexpected output %q with %d extractor calls", buf.String(), calls)
+  }
+}
@@ -0,0 +1,128 @@
+package logging
+// Contains definitions for handling critical log messages.
+
+import (
+  "os"
+  "strings"
+)
+
+// Available actions performed after a CRITICAL or FATAL message has been logged.
+const (
+  // Invoke a panic with a *CriticalError value. This is the default action.
+  CRITICAL_PANIC = iota
+  // Flush all output channels and terminate the program with the configured exit code.
+  CRITICAL_EXIT
+  // Call the configured callback function.
+  CRITICAL_CALLBACK
+  // Do nothing after logging the message.
+  CRITICAL_NONE
+)
+
+// Used internally. Terminates the program. Can be replaced for testing purposes.
+var osExit = os.Exit
+
+
+// CriticalError is the panic value used by the critical action CRITICAL_PANIC.
+type CriticalError struct {
+  Entry Entry   // The critical log entry
+}
+
+// Error returns the message of the critical log entry.
+func (e *CriticalError) Error() string {
+  return strings.TrimSuffix(e.Entry.Message, "\n")
+}
+
+
+// GetCriticalAction returns the action performed after a CRITICAL message has been logged.
+func (l *Logger) GetCriticalAction() int {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.criticalAction
+}
+
+// Global logger: GetCriticalAction returns the action performed after a CRITICAL message has been logged.
+func GetCriticalAction() int { return Global().GetCriticalAction() }
+
+
+// SetCriticalAction defines the action performed after a CRITICAL message has been logged.
+//
+// Supported actions: CRITICAL_PANIC, CRITICAL_EXIT, CRITICAL_CALLBACK and CRITICAL_NONE. The action is performed
+// regardless of verbosity level and output errors. Unsupported actions are ignored.
+func (l *Logger) SetCriticalAction(action int) {
+  if action < CRITICAL_PANIC || action > CRITICAL_NONE { return }
+  l.mutex.Lock()
+  l.criticalAction = action
+  l.mutex.Unlock()
+}
+
+// Global logger: SetCriticalAction defines the action performed after a CRITICAL message has been logged.
+//
+// Supported actions: CRITICAL_PANIC, CRITICAL_EXIT, CRITICAL_CALLBACK and CRITICAL_NONE. The action is performed
+// regardless of verbosity level and output errors. Unsupported actions are ignored.
+func SetCriticalAction(action int) { Global().SetCriticalAction(action) }
+
+
+// GetExitCode returns the exit code used by the critical action CRITICAL_EXIT.
+func (l *Logger) GetExitCode() int {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.exitCode
+}
+
+// Global logger: GetExitCode returns the exit code used by the critical action CRITICAL_EXIT.
+func GetExitCode() int { return Global().GetExitCode() }
+
+
+// SetExitCode defines the exit code used by the critical action CRITICAL_EXIT. Default exit code is 1.
+func (l *Logger) SetExitCode(code int) {
+  l.mutex.Lock()
+  l.exitCode = code
+  l.mutex.Unlock()
+}
+
+// Global logger: SetExitCode defines the exit code used by the critical action CRITICAL_EXIT. Default exit code is 1.
+func SetExitCode(code int) { Global().SetExitCode(code) }
+
+
+// GetCriticalCallback returns the function called by the critical action CRITICAL_CALLBACK.
+func (l *Logger) GetCriticalCallback() func(entry *Entry) {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.criticalCallback
+}
+
+// Global logger: GetCriticalCallback returns the function called by the critical action CRITICAL_CALLBACK.
+func GetCriticalCallback() func(entry *Entry) { return Global().GetCriticalCallback() }
+
+
+// SetCriticalCallback defines the function called by the critical action CRITICAL_CALLBACK.
+//
+// The callback receives the critical log entry. Specify nil to do nothing.
+func (l *Logger) SetCriticalCallback(callback func(entry *Entry)) {
+  l.mutex.Lock()
+  l.criticalCallback = callback
+  l.mutex.Unlock()
+}
+
+// Global logger: SetCriticalCallback defines the function called by the critical action CRITICAL_CALLBACK.
+//
+// The callback receives the critical log entry. Specify nil to do nothing.
+func SetCriticalCallback(callback func(entry *Entry)) { Global().SetCriticalCallback(callback) }
+
+
+// Used internally. Performs the critical action for the given log entry.
+func (l *Logger) critical(entry *Entry) {
+  l.mutex.RLock()
+  action, code, callback := l.criticalAction, l.exitCode, l.criticalCallback
+  l.mutex.RUnlock()
+
+  switch action {
+    case CRITICAL_PANIC:
+      panic(&CriticalError{Entry: *entry})
+    case CRITICAL_EXIT:
+      l.Flush()
+      osExit(code)
+    case CRITICAL_CALLBACK:
+      if callback != nil { callback(entry) }
+  }
+}
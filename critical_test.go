@@ -0,0 +1,79 @@
+package logging
+
+import (
+  "bytes"
+  "testing"
+)
+
+// A Writer which records whether it has been flushed.
+type flushWriter struct {
+  bytes.Buffer
+  flushed bool
+}
+
+func (w *flushWriter) Flush() error {
+  w.flushed = true
+  return nil
+}
+
+func TestCriticalPanic(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetVerbosity(CRITICAL)
+  l.SetOutput(CRITICAL, &buf)
+
+  defer func() {
+    err, ok := recover().(*CriticalError)
+    if !ok {
+      t.Fatal("expected panic with *CriticalError")
+    }
+    if err.Error() != "fatal 42" || err.Entry.Level != CRITICAL {
+      t.Errorf("unexpected panic value: %v", err)
+    }
+    if buf.String() != "fatal 42\n" {
+      t.Errorf("critical message not logged before panic: %q", buf.String())
+    }
+  }()
+  l.Criticalf("fatal %d\n", 42)
+}
+
+func TestCriticalExit(t *testing.T) {
+  code := -1
+  exit := osExit
+  osExit = func(c int) { code = c }
+  defer func() { osExit = exit }()
+
+  var w flushWriter
+  l := NewLogger()
+  l.SetOutput(CRITICAL, &w)
+  l.SetCriticalAction(CRITICAL_EXIT)
+  l.SetExitCode(3)
+  l.Criticalw("shutting down", "reason", "test")
+
+  if code != 3 {
+    t.Errorf("expected exit code 3, got %d", code)
+  }
+  if !w.flushed || w.String() != "shutting down reason=test\n" {
+    t.Errorf("expected flushed output, got %q (flushed=%v)", w.String(), w.flushed)
+  }
+}
+
+func TestCriticalCallback(t *testing.T) {
+  var buf bytes.Buffer
+  var entry *Entry
+  l := NewLogger()
+  l.SetOutput(CRITICAL, &buf)
+  l.SetCriticalAction(CRITICAL_CALLBACK)
+  l.SetCriticalCallback(func(e *Entry) { entry = e })
+  l.Criticalln("callback")
+
+  if entry == nil || entry.Message != "callback\n" || buf.String() != "callback\n" {
+    t.Errorf("unexpected callback entry: %v, output: %q", entry, buf.String())
+  }
+
+  l.SetCriticalAction(CRITICAL_NONE)
+  l.Critical("none\n")
+  if buf.String() != "callback\nnone\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
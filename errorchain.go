@@ -0,0 +1,142 @@
+package logging
+// Contains definitions for logging errors with their chain of wrapped causes.
+
+import (
+  "fmt"
+  "reflect"
+  "strings"
+)
+
+// Max. nesting depth of causes recorded by an ErrorChain.
+const ERROR_CHAIN_DEPTH = 32
+
+// ErrorChain is a field value which describes an error and the errors it wraps.
+//
+// Causes are determined by the methods "Unwrap() error" and "Unwrap() []error", which are used by errors.Unwrap and
+// errors.Join. Stack traces are extracted from errors which provide one of the methods "Callers() []uintptr" or
+// "StackTrace()" returning a slice of program counters, as defined by github.com/pkg/errors, and from *CriticalError.
+// TextFormatter prints the causes as indented list below the log message, JSONFormatter writes the chain as nested
+// objects and SlogWriter as nested groups. Other formatters write the error message only.
+type ErrorChain struct {
+  Message string        `json:"msg"`                   // Error message
+  Type    string        `json:"type"`                  // Go type of the error, e.g. "*fs.PathError"
+  Stack   string        `json:"stacktrace,omitempty"`  // Stack trace carried by the error. Empty if unavailable.
+  Causes  []*ErrorChain `json:"causes,omitempty"`      // Wrapped errors. Errors created by errors.Join have multiple causes.
+}
+
+
+// NewErrorChain returns the description of the error and the chain of its causes. Returns nil if err is nil.
+func NewErrorChain(err error) *ErrorChain {
+  return newErrorChain(err, 0)
+}
+
+
+// ErrChain returns a Field with the key "error" which describes the error and the chain of its causes.
+func ErrChain(err error) Field { return ErrChainKey("error", err) }
+
+// ErrChainKey returns a Field with the given key which describes the error and the chain of its causes.
+func ErrChainKey(key string, err error) Field {
+  if err == nil { return Field{Key: key, Value: nil} }
+  return Field{Key: key, Value: NewErrorChain(err)}
+}
+
+
+// ErrorErr prints the message followed by the given key/value pairs and the error with the chain of its causes,
+// if current verbosity is set to ERROR or lower. The log entry is terminated by a newline.
+//
+// The error is added as field "error", see ErrChain. Fields are specified as alternating keys and values, such as
+// ErrorErr(err, "msg", "user", 42), or as Field objects.
+func (l *Logger) ErrorErr(err error, msg string, keysAndValues ...interface{}) {
+  // the chain is only described if the entry passes filtering
+  caller, enabled := l.filterEntry(ERROR)
+  if !enabled { return }
+  l.logFiltered(ERROR, caller, enabled, appendFields(l.fields, append(makeFields(keysAndValues), ErrChain(err))), "%s\n", msg)
+}
+
+// Global logger: ErrorErr prints the message followed by the given key/value pairs and the error with the chain of
+// its causes, if current verbosity is set to ERROR or lower. The log entry is terminated by a newline.
+//
+// The error is added as field "error", see ErrChain. Fields are specified as alternating keys and values, such as
+// ErrorErr(err, "msg", "user", 42), or as Field objects.
+func ErrorErr(err error, msg string, keysAndValues ...interface{}) { Global().ErrorErr(err, msg, keysAndValues...) }
+
+
+// String returns the error message.
+func (c *ErrorChain) String() string {
+  return c.Message
+}
+
+
+// Used internally. Describes the error and its causes up to the max. nesting depth.
+func newErrorChain(err error, depth int) *ErrorChain {
+  if err == nil { return nil }
+  c := &ErrorChain{Message: err.Error(), Type: fmt.Sprintf("%T", err), Stack: errorStack(err)}
+  if depth >= ERROR_CHAIN_DEPTH { return c }
+  var causes []error
+  switch e := err.(type) {
+    case interface{ Unwrap() []error }: causes = e.Unwrap()
+    case interface{ Unwrap() error }:   causes = []error{e.Unwrap()}
+  }
+  for _, cause := range causes {
+    if cause != nil { c.Causes = append(c.Causes, newErrorChain(cause, depth + 1)) }
+  }
+  return c
+}
+
+
+// Used internally. Returns whether the chain contains information which is not included in the error message.
+func (c *ErrorChain) detailed() bool {
+  return len(c.Causes) > 0 || len(c.Stack) > 0
+}
+
+
+// Used internally. Writes the error and its causes as indented list. Continuation lines of multi-line messages and
+// stack traces are indented below their error.
+func (c *ErrorChain) write(sb *strings.Builder, label, indent string) {
+  msg := strings.ReplaceAll(strings.TrimSuffix(c.Message, "\n"), "\n", "\n" + indent + "  ")
+  fmt.Fprintf(sb, "%s%s (%s): %s\n", indent, label, c.Type, msg)
+  if len(c.Stack) > 0 {
+    for _, line := range strings.Split(strings.TrimSuffix(c.Stack, "\n"), "\n") {
+      sb.WriteString(indent + "  ")
+      sb.WriteString(line)
+      sb.WriteByte('\n')
+    }
+  }
+  for _, cause := range c.Causes {
+    cause.write(sb, "cause", indent + "  ")
+  }
+}
+
+
+// Used internally. Returns the stack trace carried by the error in the layout of runtime stack dumps.
+func errorStack(err error) string {
+  var pc []uintptr
+  switch e := err.(type) {
+    case *CriticalError:
+      return e.Entry.Stack
+    case interface{ Callers() []uintptr }:
+      pc = e.Callers()
+    default:
+      pc = stackTracePCs(err)
+  }
+  var sb strings.Builder
+  writeFrames(&sb, pc)
+  return sb.String()
+}
+
+
+// Used internally. Returns the program counters provided by a method "StackTrace()" of the error, which returns
+// a slice of values with underlying type uintptr, such as github.com/pkg/errors.StackTrace.
+func stackTracePCs(err error) []uintptr {
+  m := reflect.ValueOf(err).MethodByName("StackTrace")
+  if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 { return nil }
+  t := m.Type().Out(0)
+  if t.Kind() != reflect.Slice || t.Elem().Kind() != reflect.Uintptr { return nil }
+  v := m.Call(nil)[0]
+  pc := make([]uintptr, v.Len())
+  for i := range pc {
+    pc[i] = uintptr(v.Index(i).Uint())
+  }
+  return pc
+}
+
@@ -0,0 +1,117 @@
+package logging
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "runtime"
+  "strings"
+  "testing"
+)
+
+// A program counter as used by github.com/pkg/errors.
+type testFrame uintptr
+
+// An error which carries a stack trace like errors of github.com/pkg/errors.
+type stackError struct {
+  msg     string
+  frames  []testFrame
+}
+
+func (e *stackError) Error() string { return e.msg }
+
+func (e *stackError) StackTrace() []testFrame { return e.frames }
+
+func newStackError(msg string) error {
+  pc := make([]uintptr, 1)
+  runtime.Callers(2, pc)
+  return &stackError{msg: msg, frames: []testFrame{testFrame(pc[0])}}
+}
+
+// An error which counts the calls of its Error method.
+type countingError struct {
+  calls int
+}
+
+func (e *countingError) Error() string {
+  e.calls++
+  return "counted"
+}
+
+func TestErrorChain(t *testing.T) {
+  base := errors.New("disk full")
+  joined := errors.Join(base, newStackError("quota exceeded"))
+  err := fmt.Errorf("save failed: %w", joined)
+
+  chain := NewErrorChain(err)
+  if chain.Message != err.Error() || chain.Type != "*fmt.wrapError" || len(chain.Causes) != 1 {
+    t.Fatalf("unexpected chain: %+v", chain)
+  }
+  causes := chain.Causes[0].Causes
+  if len(causes) != 2 || causes[0].Message != "disk full" || causes[0].Type != "*errors.errorString" {
+    t.Fatalf("unexpected causes: %+v", chain.Causes[0])
+  }
+  if !strings.Contains(causes[1].Stack, ".TestErrorChain(...)\n\t") || strings.Count(causes[1].Stack, "\n") != 2 {
+    t.Errorf("unexpected stack trace: %q", causes[1].Stack)
+  }
+  if NewErrorChain(nil) != nil || ErrChain(nil).Value != nil {
+    t.Errorf("unexpected chain of nil error")
+  }
+}
+
+
+func TestErrorErr(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  err := fmt.Errorf("save failed: %w", errors.Join(errors.New("disk full"), errors.New("quota exceeded")))
+  l.ErrorErr(err, "request failed", "id", 7)
+  expected := "request failed id=7 error=\"save failed: disk full\\nquota exceeded\"\n" +
+              "  error (*fmt.wrapError): save failed: disk full\n" +
+              "    quota exceeded\n" +
+              "    cause (*errors.joinError): disk full\n" +
+              "      quota exceeded\n" +
+              "      cause (*errors.errorString): disk full\n" +
+              "      cause (*errors.errorString): quota exceeded\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  buf.Reset()
+  l.ErrorErr(errors.New("plain"), "no causes")
+  if buf.String() != "no causes error=plain\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+
+  buf.Reset()
+  l.SetFormatter(&JSONFormatter{TimeKey: KEY_OMIT})
+  l.ErrorErr(fmt.Errorf("save failed: %w", errors.New("disk full")), "request failed")
+  expected = `{"level":"error","msg":"request failed","error":{"msg":"save failed: disk full","type":"*fmt.wrapError",` +
+             `"causes":[{"msg":"disk full","type":"*errors.errorString"}]}}` + "\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+  var obj map[string]interface{}
+  if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+    t.Errorf("invalid JSON %q: %v", buf.String(), err)
+  }
+}
+
+
+func TestErrorErrFiltered(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  l.SetVerbosity(CRITICAL)
+  err := &countingError{}
+  l.ErrorErr(err, "filtered")
+  if buf.Len() > 0 || err.calls > 0 {
+    t.Errorf("filtered entry was described: %q, %d calls", buf.String(), err.calls)
+  }
+  l.SetVerbosity(ERROR)
+  l.ErrorErr(err, "logged")
+  if buf.String() != "logged error=counted\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
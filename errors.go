@@ -0,0 +1,217 @@
+package logging
+// Contains the handling of errors which occur while writing log entries.
+
+import (
+  "bytes"
+  "errors"
+  "fmt"
+  "io"
+  "os"
+  "sync"
+  "time"
+)
+
+// Stats contains counters of write operations of a Logger.
+type Stats struct {
+  Writes    uint64  // Number of log entries written successfully, counted per output channel
+  Failures  uint64  // Number of log entries which could not be written after all retries
+  Retries   uint64  // Number of repeated write attempts
+  Fallbacks uint64  // Number of log entries written to the fallback writer
+}
+
+// Used internally. Tracks write operations of a Logger.
+type writeStats struct {
+  mutex   sync.Mutex
+  stats   Stats
+  err     error     // most recent error
+}
+
+
+// GetErrorHandler returns the function which is called if writing a log entry fails. Returns nil if undefined.
+func (l *Logger) GetErrorHandler() func(err error, entry *Entry) {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.errorHandler
+}
+
+// Global logger: GetErrorHandler returns the function which is called if writing a log entry fails.
+// Returns nil if undefined.
+func GetErrorHandler() func(err error, entry *Entry) { return Global().GetErrorHandler() }
+
+
+// SetErrorHandler defines a function which is called if writing a log entry fails after all retries.
+//
+// The function is called for errors of hooks as well. The entry argument is nil if the error is not associated with
+// a single log entry. Failed log entries are written to the fallback writer before the function is called. The
+// function must not log through the same Logger. Errors are printed to os.Stderr if no handler is defined, which is
+// the default. Errors of sinks with their own error handler are not passed to this function.
+func (l *Logger) SetErrorHandler(handler func(err error, entry *Entry)) {
+  l.mutex.Lock()
+  l.errorHandler = handler
+  l.mutex.Unlock()
+}
+
+// Global logger: SetErrorHandler defines a function which is called if writing a log entry fails after all retries.
+//
+// The function is called for errors of hooks as well. The entry argument is nil if the error is not associated with
+// a single log entry. Failed log entries are written to the fallback writer before the function is called. Errors
+// are printed to os.Stderr if no handler is defined, which is the default. Errors of sinks with their own error
+// handler are not passed to this function.
+func SetErrorHandler(handler func(err error, entry *Entry)) { Global().SetErrorHandler(handler) }
+
+
+// GetFallback returns the Writer object which receives log entries that could not be written. Returns nil if undefined.
+func (l *Logger) GetFallback() io.Writer {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.fallback
+}
+
+// Global logger: GetFallback returns the Writer object which receives log entries that could not be written.
+// Returns nil if undefined.
+func GetFallback() io.Writer { return Global().GetFallback() }
+
+
+// SetFallback defines a Writer object, such as os.Stderr, which receives log entries that could not be written
+// to their output channel. Log entries are formatted by the Formatter of the Logger. Specify nil to discard failed
+// log entries, which is the default.
+func (l *Logger) SetFallback(writer io.Writer) {
+  l.mutex.Lock()
+  l.fallback = writer
+  l.mutex.Unlock()
+}
+
+// Global logger: SetFallback defines a Writer object, such as os.Stderr, which receives log entries that could
+// not be written to their output channel. Log entries are formatted by the Formatter of the Logger. Specify nil to
+// discard failed log entries, which is the default.
+func SetFallback(writer io.Writer) { Global().SetFallback(writer) }
+
+
+// GetRetry returns the max. number of retries and the initial delay between retries of failed writes.
+func (l *Logger) GetRetry() (int, time.Duration) {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.retryAttempts, l.retryBackoff
+}
+
+// Global logger: GetRetry returns the max. number of retries and the initial delay between retries of failed writes.
+func GetRetry() (int, time.Duration) { return Global().GetRetry() }
+
+
+// SetRetry defines how often writing a log entry is repeated if it fails with a transient error.
+//
+// The delay is doubled after every retry. Errors are transient if they provide a method "Temporary() bool" or
+// "Timeout() bool" which returns true, or if they are io.ErrShortWrite. Retries block all log calls of the Logger.
+// Specify 0 attempts to disable retries, which is the default.
+func (l *Logger) SetRetry(attempts int, backoff time.Duration) {
+  if attempts < 0 { attempts = 0 }
+  if backoff < 0 { backoff = 0 }
+  l.mutex.Lock()
+  l.retryAttempts, l.retryBackoff = attempts, backoff
+  l.mutex.Unlock()
+}
+
+// Global logger: SetRetry defines how often writing a log entry is repeated if it fails with a transient error.
+//
+// The delay is doubled after every retry. Errors are transient if they provide a method "Temporary() bool" or
+// "Timeout() bool" which returns true, or if they are io.ErrShortWrite. Retries block all log calls of the Logger.
+// Specify 0 attempts to disable retries, which is the default.
+func SetRetry(attempts int, backoff time.Duration) { Global().SetRetry(attempts, backoff) }
+
+
+// Err returns the most recent error which occurred while writing a log entry. Returns nil if no error occurred.
+//
+// The global Logger provides no counterpart of this function, since Err is the constructor of error fields.
+// Use Global().Err() instead.
+func (l *Logger) Err() error {
+  l.stats.mutex.Lock()
+  defer l.stats.mutex.Unlock()
+  return l.stats.err
+}
+
+
+// GetStats returns the counters of write operations of the Logger.
+func (l *Logger) GetStats() Stats {
+  l.stats.mutex.Lock()
+  defer l.stats.mutex.Unlock()
+  return l.stats.stats
+}
+
+// Global logger: GetStats returns the counters of write operations of the global Logger.
+func GetStats() Stats { return Global().GetStats() }
+
+
+// Used internally. Calls the write function and repeats it on transient errors. Updates the write counters.
+func (l *Logger) retry(write func() error) error {
+  err := write()
+  if err != nil && isTransient(err) {
+    l.mutex.RLock()
+    attempts, backoff := l.retryAttempts, l.retryBackoff
+    l.mutex.RUnlock()
+    for i := 0; i < attempts && err != nil && isTransient(err); i++ {
+      time.Sleep(backoff)
+      backoff *= 2
+      l.stats.mutex.Lock()
+      l.stats.stats.Retries++
+      l.stats.mutex.Unlock()
+      err = write()
+    }
+  }
+  l.stats.mutex.Lock()
+  if err == nil {
+    l.stats.stats.Writes++
+  } else {
+    l.stats.stats.Failures++
+    l.stats.err = err
+  }
+  l.stats.mutex.Unlock()
+  return err
+}
+
+
+// Used internally. Handles an error which occurred while writing the log entry to its output channel.
+//
+// The entry is written to the fallback writer, then the error is passed to the error handler.
+func (l *Logger) writeFailed(err error, entry *Entry) {
+  l.mutex.RLock()
+  fallback, formatter := l.fallback, l.formatter
+  l.mutex.RUnlock()
+
+  if fallback != nil {
+    var buf bytes.Buffer
+    if formatter.Format(&buf, entry) == nil {
+      l.writeMutex.Lock()
+      _, ferr := fallback.Write(buf.Bytes())
+      l.writeMutex.Unlock()
+      if ferr == nil {
+        l.stats.mutex.Lock()
+        l.stats.stats.Fallbacks++
+        l.stats.mutex.Unlock()
+      }
+    }
+  }
+  l.handleError(err, entry)
+}
+
+
+// Used internally. Passes the error to the error handler or prints it to stderr. The entry may be nil.
+func (l *Logger) handleError(err error, entry *Entry) {
+  l.mutex.RLock()
+  handler := l.errorHandler
+  l.mutex.RUnlock()
+  if handler != nil {
+    handler(err, entry)
+  } else {
+    fmt.Fprintf(os.Stderr, "logging: %v\n", err)
+  }
+}
+
+
+// Used internally. Returns whether the error is transient.
+func isTransient(err error) bool {
+  if errors.Is(err, io.ErrShortWrite) { return true }
+  var temporary interface{ Temporary() bool }
+  if errors.As(err, &temporary) && temporary.Temporary() { return true }
+  var timeout interface{ Timeout() bool }
+  return errors.As(err, &timeout) && timeout.Timeout()
+}
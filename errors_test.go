@@ -0,0 +1,87 @@
+package logging
+
+import (
+  "bytes"
+  "errors"
+  "strings"
+  "testing"
+  "time"
+)
+
+// An error which reports itself as temporary.
+type temporaryError struct{}
+
+func (e temporaryError) Error() string { return "resource temporarily unavailable" }
+func (e temporaryError) Temporary() bool { return true }
+
+// A Writer which fails a given number of times before it accepts data.
+type flakyWriter struct {
+  failures  int
+  err       error
+  buf       bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+  if w.failures > 0 {
+    w.failures--
+    return 0, w.err
+  }
+  return w.buf.Write(p)
+}
+
+func TestWriteRetry(t *testing.T) {
+  w := &flakyWriter{failures: 2, err: temporaryError{}}
+  l := NewLogger()
+  l.SetOutput(INFO, w)
+  l.SetRetry(3, time.Millisecond)
+  if attempts, backoff := l.GetRetry(); attempts != 3 || backoff != time.Millisecond {
+    t.Errorf("unexpected retry settings: %d, %v", attempts, backoff)
+  }
+
+  l.Infoln("retried")
+  if w.buf.String() != "retried\n" {
+    t.Errorf("unexpected output: %q", w.buf.String())
+  }
+  if stats := l.GetStats(); stats != (Stats{Writes: 1, Retries: 2}) || l.Err() != nil {
+    t.Errorf("unexpected stats: %+v, %v", stats, l.Err())
+  }
+
+  // permanent errors are not retried
+  w.failures, w.err = 2, errors.New("broken pipe")
+  stderr := captureStderr(t, func() { l.Infoln("lost") })
+  if !strings.Contains(stderr, "logging: broken pipe") {
+    t.Errorf("error not reported: %q", stderr)
+  }
+  if stats := l.GetStats(); stats != (Stats{Writes: 1, Failures: 1, Retries: 2}) || l.Err() != w.err {
+    t.Errorf("unexpected stats: %+v, %v", stats, l.Err())
+  }
+}
+
+
+func TestWriteFallback(t *testing.T) {
+  var fallback bytes.Buffer
+  var handled []string
+  w := &flakyWriter{failures: 1, err: errors.New("disk full")}
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(WARN, w)
+  l.SetFallback(&fallback)
+  l.SetErrorHandler(func(err error, entry *Entry) {
+    handled = append(handled, err.Error() + ": " + entry.Message)
+  })
+  if l.GetFallback() != &fallback || l.GetErrorHandler() == nil {
+    t.Error("unexpected error settings")
+  }
+
+  l.Warnln("first")
+  l.Warnln("second")
+  if fallback.String() != "WARN first\n" || w.buf.String() != "WARN second\n" {
+    t.Errorf("unexpected output: %q, fallback: %q", w.buf.String(), fallback.String())
+  }
+  if len(handled) != 1 || handled[0] != "disk full: first\n" {
+    t.Errorf("unexpected errors: %q", handled)
+  }
+  if stats := l.GetStats(); stats != (Stats{Writes: 1, Failures: 1, Fallbacks: 1}) {
+    t.Errorf("unexpected stats: %+v", stats)
+  }
+}
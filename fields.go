@@ -0,0 +1,168 @@
+package logging
+// Contains definitions for structured log entry fields.
+
+import (
+  "fmt"
+  "strconv"
+  "strings"
+  "time"
+  "unicode"
+)
+
+// Key used for values which could not be associated with a valid field key.
+const BadKey = "!BADKEY"
+
+// Field is a key/value pair that can be attached to log entries.
+type Field struct {
+  Key   string
+  Value interface{}
+}
+
+// Fields is an ordered list of key/value pairs.
+type Fields []Field
+
+
+// String returns a Field with the given key and string value.
+func String(key, value string) Field { return Field{Key: key, Value: value} }
+
+// Int returns a Field with the given key and int value.
+func Int(key string, value int) Field { return Field{Key: key, Value: value} }
+
+// Int64 returns a Field with the given key and int64 value.
+func Int64(key string, value int64) Field { return Field{Key: key, Value: value} }
+
+// Uint returns a Field with the given key and uint value.
+func Uint(key string, value uint) Field { return Field{Key: key, Value: value} }
+
+// Uint64 returns a Field with the given key and uint64 value.
+func Uint64(key string, value uint64) Field { return Field{Key: key, Value: value} }
+
+// Float64 returns a Field with the given key and float64 value.
+func Float64(key string, value float64) Field { return Field{Key: key, Value: value} }
+
+// Bool returns a Field with the given key and bool value.
+func Bool(key string, value bool) Field { return Field{Key: key, Value: value} }
+
+// Duration returns a Field with the given key and duration value.
+func Duration(key string, value time.Duration) Field { return Field{Key: key, Value: value} }
+
+// Time returns a Field with the given key and time value.
+func Time(key string, value time.Time) Field { return Field{Key: key, Value: value} }
+
+// Err returns a Field with the key "error" and the given error value.
+func Err(err error) Field { return Field{Key: "error", Value: err} }
+
+// Any returns a Field with the given key and an arbitrary value.
+func Any(key string, value interface{}) Field { return Field{Key: key, Value: value} }
+
+
+// Used internally. Converts a list of alternating keys and values into a list of fields.
+//
+// Field objects in the list are added as is. Values without a valid key are added with the key BadKey.
+func makeFields(keysAndValues []interface{}) Fields {
+  if len(keysAndValues) == 0 { return nil }
+  fields := make(Fields, 0, (len(keysAndValues) + 1) / 2)
+  for i := 0; i < len(keysAndValues); i++ {
+    switch v := keysAndValues[i].(type) {
+      case Field:
+        fields = append(fields, v)
+      case Fields:
+        fields = append(fields, v...)
+      case string:
+        if i + 1 < len(keysAndValues) {
+          fields = append(fields, Field{Key: v, Value: keysAndValues[i+1]})
+          i++
+        } else {
+          fields = append(fields, Field{Key: BadKey, Value: v})
+        }
+      default:
+        fields = append(fields, Field{Key: BadKey, Value: v})
+    }
+  }
+  return fields
+}
+
+
+// Used internally. Returns a new list containing the fields of both lists. Does not modify the given lists.
+func appendFields(fields, more Fields) Fields {
+  if len(more) == 0 { return fields }
+  if len(fields) == 0 { return more }
+  result := make(Fields, 0, len(fields) + len(more))
+  result = append(result, fields...)
+  return append(result, more...)
+}
+
+
+// Used internally. Returns the key of a field written by a structured formatter. Keys which equal the key of a
+// built-in element are prefixed by "fields.", e.g. "fields.msg", so that every key of the log entry is unique.
+func fieldKey(key string, builtin []string) string {
+  for _, b := range builtin {
+    if key == b && b != KEY_OMIT { return "fields." + key }
+  }
+  return key
+}
+
+
+// Used internally. Returns the textual representation of a field value.
+func formatFieldValue(value interface{}) string {
+  switch v := value.(type) {
+    case nil:
+      return "<nil>"
+    case string:
+      return v
+    case error:
+      return v.Error()
+    case time.Time:
+      return v.Format(time.RFC3339Nano)
+    case fmt.Stringer:
+      return v.String()
+    default:
+      return fmt.Sprint(v)
+  }
+}
+
+
+// Used internally. Returns whether the given string must be quoted to be unambiguously parsed as a field value.
+func needsQuoting(s string) bool {
+  if len(s) == 0 { return true }
+  for _, r := range s {
+    if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r == 0xfffd {
+      return true
+    }
+  }
+  return false
+}
+
+
+// Used internally. Writes the fields as space-separated key=value pairs. Values are quoted if needed.
+func writeFields(sb *strings.Builder, fields Fields) {
+  for i, f := range fields {
+    if i > 0 { sb.WriteByte(' ') }
+    writeFieldPair(sb, f.Key, formatFieldValue(f.Value))
+  }
+}
+
+
+// Used internally. Writes a single key=value pair. Invalid characters in the key are replaced by underscores.
+// The value is quoted if needed.
+func writeFieldPair(sb *strings.Builder, key, value string) {
+  if len(key) == 0 { key = BadKey }
+  for _, r := range key {
+    if r <= ' ' || r == '=' || r == '"' || r == 0x7f || !unicode.IsPrint(r) {
+      r = '_'
+    }
+    sb.WriteRune(r)
+  }
+  sb.WriteByte('=')
+  writeFieldValue(sb, value)
+}
+
+
+// Used internally. Writes the field value. The value is quoted if needed.
+func writeFieldValue(sb *strings.Builder, value string) {
+  if needsQuoting(value) {
+    sb.WriteString(strconv.Quote(value))
+  } else {
+    sb.WriteString(value)
+  }
+}
@@ -0,0 +1,29 @@
+package logging
+
+import (
+  "bytes"
+  "errors"
+  "testing"
+  "time"
+)
+
+func TestFields(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(WARN, &buf)
+
+  l.Infow("login", "user", 42, "ip", "10.0.0.1")
+  l.Warnw("failed", Err(errors.New("access denied")), Duration("took", 1500 * time.Millisecond), "dangling")
+  l.Infow("", String("empty", ""), Bool("ok", true), Any("q", `a="b"`))
+  l.Infow("typed", Int64("i", -1), Uint("u", 2), Float64("f", 0.5), Time("t", time.Date(2018, 6, 3, 12, 0, 0, 0, time.UTC)))
+
+  expected := "INFO login user=42 ip=10.0.0.1\n" +
+              "WARN failed error=\"access denied\" took=1.5s " + BadKey + "=dangling\n" +
+              "INFO empty=\"\" ok=true q=\"a=\\\"b\\\"\"\n" +
+              "INFO typed i=-1 u=2 f=0.5 t=2018-06-03T12:00:00Z\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+}
@@ -0,0 +1,134 @@
+package logging
+// Contains helpers for configuring the verbosity level by command line flags.
+
+import (
+  "flag"
+  "strconv"
+)
+
+// Used internally. A flag.Value which sets the verbosity level of a Logger.
+type verbosityFlag struct {
+  logger *Logger
+}
+
+// Used internally. A flag.Value which sets the per-package and per-file verbosity rules of a Logger.
+type vmoduleFlag struct {
+  logger *Logger
+}
+
+// Used internally. A repeatable boolean flag which adjusts the verbosity level of a Logger on every occurrence.
+type verbosityStepFlag struct {
+  logger  *Logger
+  dir     int   // > 0 for more output, < 0 for less output
+}
+
+
+// VerbosityFlag defines a flag with the given name which sets the verbosity level of the Logger.
+//
+// The flag accepts level names, short names and numbers, e.g. "-level=warn", "-level=ERRO" or "-level=20".
+// The flag is defined in the given FlagSet, or in flag.CommandLine if the FlagSet is nil.
+func (l *Logger) VerbosityFlag(fs *flag.FlagSet, name, usage string) {
+  if fs == nil { fs = flag.CommandLine }
+  fs.Var(&verbosityFlag{logger: l}, name, usage)
+}
+
+// Global logger: VerbosityFlag defines a flag with the given name which sets the verbosity level of the Logger.
+//
+// The flag accepts level names, short names and numbers, e.g. "-level=warn", "-level=ERRO" or "-level=20".
+// The flag is defined in the given FlagSet, or in flag.CommandLine if the FlagSet is nil.
+func VerbosityFlag(fs *flag.FlagSet, name, usage string) { Global().VerbosityFlag(fs, name, usage) }
+
+
+// VerbosityFlags defines repeatable boolean flags which adjust the verbosity level of the Logger.
+//
+// Every occurrence of the flag "verbose" (e.g. "-v -v") calls DecreaseVerbosity, so that messages of the next lower
+// level are logged as well. Every occurrence of the flag "quiet" (e.g. "-q") calls IncreaseVerbosity. Empty names
+// skip the respective flag. The flags are defined in the given FlagSet, or in flag.CommandLine if the FlagSet is nil.
+func (l *Logger) VerbosityFlags(fs *flag.FlagSet, verbose, quiet string) {
+  if fs == nil { fs = flag.CommandLine }
+  if len(verbose) > 0 {
+    fs.Var(&verbosityStepFlag{logger: l, dir: 1}, verbose, "log more messages (repeatable)")
+  }
+  if len(quiet) > 0 {
+    fs.Var(&verbosityStepFlag{logger: l, dir: -1}, quiet, "log fewer messages (repeatable)")
+  }
+}
+
+// Global logger: VerbosityFlags defines repeatable boolean flags which adjust the verbosity level of the Logger.
+//
+// Every occurrence of the flag "verbose" (e.g. "-v -v") calls DecreaseVerbosity, so that messages of the next lower
+// level are logged as well. Every occurrence of the flag "quiet" (e.g. "-q") calls IncreaseVerbosity. Empty names
+// skip the respective flag. The flags are defined in the given FlagSet, or in flag.CommandLine if the FlagSet is nil.
+func VerbosityFlags(fs *flag.FlagSet, verbose, quiet string) { Global().VerbosityFlags(fs, verbose, quiet) }
+
+
+// VModuleFlag defines a flag with the given name which sets the per-package and per-file verbosity rules of the Logger.
+//
+// The flag accepts rules in the format of SetVModule, e.g. "-vmodule=pkg/db/*=LOG,main=WARN".
+// The flag is defined in the given FlagSet, or in flag.CommandLine if the FlagSet is nil.
+func (l *Logger) VModuleFlag(fs *flag.FlagSet, name, usage string) {
+  if fs == nil { fs = flag.CommandLine }
+  fs.Var(&vmoduleFlag{logger: l}, name, usage)
+}
+
+// Global logger: VModuleFlag defines a flag with the given name which sets the per-package and per-file verbosity
+// rules of the Logger.
+//
+// The flag accepts rules in the format of SetVModule, e.g. "-vmodule=pkg/db/*=LOG,main=WARN".
+// The flag is defined in the given FlagSet, or in flag.CommandLine if the FlagSet is nil.
+func VModuleFlag(fs *flag.FlagSet, name, usage string) { Global().VModuleFlag(fs, name, usage) }
+
+
+// Used internally. Returns the current verbosity level.
+func (f *verbosityFlag) String() string {
+  if f == nil || f.logger == nil { return "" }
+  return f.logger.GetVerbosity().String()
+}
+
+
+// Used internally. Parses the level and sets the verbosity level.
+func (f *verbosityFlag) Set(s string) error {
+  level, err := ParseLevel(s)
+  if err != nil { return err }
+  f.logger.SetVerbosity(level)
+  return nil
+}
+
+
+// Used internally. Returns the current verbosity rules.
+func (f *vmoduleFlag) String() string {
+  if f == nil || f.logger == nil { return "" }
+  return f.logger.GetVModule()
+}
+
+
+// Used internally. Sets the verbosity rules.
+func (f *vmoduleFlag) Set(s string) error {
+  return f.logger.SetVModule(s)
+}
+
+
+// Used internally. Marks the flag as boolean flag, so that it can be specified without value.
+func (f *verbosityStepFlag) IsBoolFlag() bool {
+  return true
+}
+
+
+// Used internally. Boolean flags have no printable default value.
+func (f *verbosityStepFlag) String() string {
+  return ""
+}
+
+
+// Used internally. Adjusts the verbosity level if the flag value is true.
+func (f *verbosityStepFlag) Set(s string) error {
+  on, err := strconv.ParseBool(s)
+  if err != nil { return err }
+  if !on { return nil }
+  if f.dir > 0 {
+    f.logger.DecreaseVerbosity()
+  } else {
+    f.logger.IncreaseVerbosity()
+  }
+  return nil
+}
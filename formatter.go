@@ -0,0 +1,107 @@
+package logging
+// Contains definitions for formatting log entries.
+
+import (
+  "io"
+  "runtime"
+  "strings"
+  "time"
+)
+
+// Entry contains all information about a single log entry.
+type Entry struct {
+  Time            time.Time       // Time of the log call
+  Level           Level           // Log level of the entry
+  Name            string          // Dotted name of the Logger. Empty for unnamed loggers.
+  Caller          runtime.Frame   // Calling function. Only available if Prefix.Caller is set, otherwise Caller.PC is 0.
+  Message         string          // The log message. May contain a terminating newline character.
+  Fields          Fields          // Structured key/value pairs, including fields bound to the Logger.
+  Prefix          PrefixOptions   // Prefix settings of the Logger at the time of the log call
+  TimestampFormat string          // Timestamp format of the Logger at the time of the log call
+  CallerFormat    int             // Caller format of the Logger at the time of the log call, see SetCallerFormat
+  Color           bool            // Whether the output channel supports ANSI colors, see SetColorMode
+  Stack           string          // Stack trace in the layout of runtime stack dumps, see SetStackTrace. Empty if undefined.
+}
+
+// HasCaller returns whether caller information is available for the log entry.
+func (e *Entry) HasCaller() bool {
+  return e.Caller.PC != 0
+}
+
+// Formatter defines the layout of log entries.
+//
+// Format is called for every log entry that passes verbosity filtering. The formatted entry should be written to
+// the specified Writer object which buffers the data until it is written to the output channel in a single operation.
+type Formatter interface {
+  Format(w io.Writer, entry *Entry) error
+}
+
+
+// TextFormatter is the default Formatter for log entries.
+//
+// It prints the log message, prefixed by the timestamp, caller and level if they are enabled in the prefix
+// settings of the Logger, followed by the fields of the log entry. The causes of ErrorChain fields and the stack
+// trace, if available, are printed in the lines below the log message. If colors are enabled for the log entry, the
+// level is printed in the color of the log level and timestamp, caller and stack trace are dimmed.
+type TextFormatter struct {
+}
+
+// NewTextFormatter returns a new TextFormatter object.
+func NewTextFormatter() *TextFormatter {
+  return &TextFormatter{}
+}
+
+// Format writes the log entry in the default text layout.
+func (f *TextFormatter) Format(w io.Writer, entry *Entry) error {
+  var sb strings.Builder
+  if entry.Prefix.Timestamp {
+    sb.WriteString(entry.colorize(COLOR_DIM, entry.Time.Format(entry.TimestampFormat)))
+    sb.WriteString(" ")
+  }
+  if entry.Prefix.Caller && entry.HasCaller() {
+    sb.WriteString(entry.colorize(COLOR_DIM, formatCaller(entry.Caller, entry.CallerFormat)))
+    sb.WriteString(" ")
+  }
+  if entry.Prefix.Level {
+    sb.WriteString(entry.colorize(entry.Level.Color(), getLevelString(entry.Level)))
+    sb.WriteString(" ")
+  }
+  if len(entry.Name) > 0 {
+    sb.WriteString(entry.Name)
+    sb.WriteString(": ")
+  }
+  if len(entry.Fields) > 0 {
+    // fields are placed in front of the terminating newline
+    text := strings.TrimSuffix(entry.Message, "\n")
+    sb.WriteString(text)
+    if len(text) > 0 { sb.WriteByte(' ') }
+    writeFields(&sb, entry.Fields)
+    sb.WriteString(entry.Message[len(text):])
+  } else {
+    sb.WriteString(entry.Message)
+  }
+  // error causes and stack trace are printed in the lines below the log message
+  var details strings.Builder
+  for _, field := range entry.Fields {
+    if chain, ok := field.Value.(*ErrorChain); ok && chain.detailed() {
+      chain.write(&details, field.Key, "  ")
+    }
+  }
+  if len(entry.Stack) > 0 {
+    details.WriteString(entry.colorize(COLOR_DIM, strings.TrimSuffix(entry.Stack, "\n")))
+    details.WriteByte('\n')
+  }
+  if details.Len() > 0 {
+    if !strings.HasSuffix(entry.Message, "\n") { sb.WriteByte('\n') }
+    sb.WriteString(details.String())
+  }
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
+
+
+// Used internally. Returns the text enclosed by the given color sequence if colors are enabled for the log entry.
+func (e *Entry) colorize(color, text string) string {
+  if !e.Color { return text }
+  return colorize(color, text)
+}
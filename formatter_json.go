@@ -0,0 +1,167 @@
+package logging
+// Contains the JSON lines formatter.
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "io"
+  "strconv"
+  "strings"
+  "time"
+)
+
+// Available timestamp encodings for structured formatters.
+const (
+  // Timestamp is encoded as string, using the timestamp layout of the formatter.
+  TS_ENC_LAYOUT = iota
+  // Timestamp is encoded as number of seconds since Unix epoch, including fractions of a second.
+  TS_ENC_UNIX
+  // Timestamp is encoded as number of milliseconds since Unix epoch.
+  TS_ENC_UNIX_MILLI
+  // Timestamp is encoded as number of nanoseconds since Unix epoch.
+  TS_ENC_UNIX_NANO
+)
+
+// Default key names used by structured formatters.
+const (
+  KEY_TIME    = "time"
+  KEY_LEVEL   = "level"
+  KEY_NAME    = "logger"
+  KEY_CALLER  = "caller"
+  KEY_MESSAGE = "msg"
+  KEY_STACK   = "stacktrace"
+)
+
+// Key name that can be used to omit an element from structured log entries.
+const KEY_OMIT = "-"
+
+
+// JSONFormatter writes log entries as JSON objects, one object per line.
+//
+// The elements time, level, logger name, caller and message are written first, in this order, followed by the fields
+// of the log entry and the stack trace. Each of logger name, caller and stack trace is only written if it is
+// available. Fields whose keys equal the key of a built-in element are prefixed by "fields.", e.g. "fields.msg".
+// The zero value is ready to use.
+type JSONFormatter struct {
+  TimeKey         string  // Key of the timestamp. Defaults to KEY_TIME if empty.
+  LevelKey        string  // Key of the log level. Defaults to KEY_LEVEL if empty.
+  NameKey         string  // Key of the Logger name. Defaults to KEY_NAME if empty.
+  CallerKey       string  // Key of the caller. Defaults to KEY_CALLER if empty.
+  MessageKey      string  // Key of the log message. Defaults to KEY_MESSAGE if empty.
+  StackKey        string  // Key of the stack trace. Defaults to KEY_STACK if empty.
+  // Encoding of the timestamp. Supported encodings: TS_ENC_LAYOUT, TS_ENC_UNIX, TS_ENC_UNIX_MILLI and TS_ENC_UNIX_NANO.
+  TimestampEncoding int
+  // Timestamp layout for TS_ENC_LAYOUT. Defaults to time.RFC3339Nano if empty.
+  TimestampFormat string
+}
+
+// NewJSONFormatter returns a new JSONFormatter object with default key names and RFC 3339 timestamps.
+func NewJSONFormatter() *JSONFormatter {
+  return &JSONFormatter{
+    TimeKey: KEY_TIME,
+    LevelKey: KEY_LEVEL,
+    NameKey: KEY_NAME,
+    CallerKey: KEY_CALLER,
+    MessageKey: KEY_MESSAGE,
+    StackKey: KEY_STACK,
+    TimestampEncoding: TS_ENC_LAYOUT,
+    TimestampFormat: time.RFC3339Nano,
+  }
+}
+
+// Format writes the log entry as a single line JSON object.
+func (f *JSONFormatter) Format(w io.Writer, entry *Entry) error {
+  var buf bytes.Buffer
+  buf.WriteByte('{')
+  first := true
+  add := func(key string, value []byte) {
+    if !first { buf.WriteByte(',') }
+    first = false
+    buf.Write(marshalJSONValue(key))
+    buf.WriteByte(':')
+    buf.Write(value)
+  }
+
+  if key := keyName(f.TimeKey, KEY_TIME); key != KEY_OMIT {
+    add(key, encodeTimestamp(entry.Time, f.TimestampEncoding, f.TimestampFormat, true))
+  }
+  if key := keyName(f.LevelKey, KEY_LEVEL); key != KEY_OMIT {
+    add(key, marshalJSONValue(getLevelName(entry.Level)))
+  }
+  if key := keyName(f.NameKey, KEY_NAME); key != KEY_OMIT && len(entry.Name) > 0 {
+    add(key, marshalJSONValue(entry.Name))
+  }
+  if key := keyName(f.CallerKey, KEY_CALLER); key != KEY_OMIT && entry.HasCaller() {
+    add(key, marshalJSONValue(formatCaller(entry.Caller, entry.CallerFormat)))
+  }
+  if key := keyName(f.MessageKey, KEY_MESSAGE); key != KEY_OMIT {
+    add(key, marshalJSONValue(strings.TrimSuffix(entry.Message, "\n")))
+  }
+  builtin := []string{
+    keyName(f.TimeKey, KEY_TIME), keyName(f.LevelKey, KEY_LEVEL), keyName(f.NameKey, KEY_NAME),
+    keyName(f.CallerKey, KEY_CALLER), keyName(f.MessageKey, KEY_MESSAGE), keyName(f.StackKey, KEY_STACK),
+  }
+  for _, field := range entry.Fields {
+    add(fieldKey(field.Key, builtin), marshalJSONValue(field.Value))
+  }
+  if key := keyName(f.StackKey, KEY_STACK); key != KEY_OMIT && len(entry.Stack) > 0 {
+    add(key, marshalJSONValue(entry.Stack))
+  }
+  buf.WriteString("}\n")
+
+  _, err := w.Write(buf.Bytes())
+  return err
+}
+
+
+// Used internally. Returns the key name, or the default key name if key is empty.
+func keyName(key, defaultKey string) string {
+  if len(key) == 0 { return defaultKey }
+  return key
+}
+
+
+// Used internally. Encodes the timestamp as specified by the encoding. Timestamp strings are quoted if requested.
+func encodeTimestamp(t time.Time, encoding int, layout string, quote bool) []byte {
+  switch encoding {
+    case TS_ENC_UNIX:
+      return strconv.AppendFloat(nil, float64(t.UnixNano()) / 1e9, 'f', -1, 64)
+    case TS_ENC_UNIX_MILLI:
+      return strconv.AppendInt(nil, t.UnixNano() / int64(time.Millisecond), 10)
+    case TS_ENC_UNIX_NANO:
+      return strconv.AppendInt(nil, t.UnixNano(), 10)
+    default:
+      if len(layout) == 0 { layout = time.RFC3339Nano }
+      s := t.Format(layout)
+      if quote { return marshalJSONValue(s) }
+      return []byte(s)
+  }
+}
+
+
+// Used internally. Returns the JSON representation of the given value.
+//
+// Errors and durations are encoded as strings. Values that cannot be encoded as JSON are encoded by
+// their string representation.
+func marshalJSONValue(value interface{}) []byte {
+  switch v := value.(type) {
+    case nil:
+      return []byte("null")
+    case json.Marshaler:
+      // custom encodings take precedence
+    case error:
+      value = v.Error()
+    case time.Duration:
+      value = v.String()
+  }
+
+  var buf bytes.Buffer
+  enc := json.NewEncoder(&buf)
+  enc.SetEscapeHTML(false)
+  if err := enc.Encode(value); err != nil {
+    buf.Reset()
+    enc.Encode(fmt.Sprint(value))
+  }
+  return bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+}
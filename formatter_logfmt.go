@@ -0,0 +1,81 @@
+package logging
+// Contains the logfmt formatter.
+
+import (
+  "io"
+  "strings"
+)
+
+// Default key name of the timestamp used by the logfmt formatter.
+const KEY_LOGFMT_TIME = "ts"
+
+
+// LogfmtFormatter writes log entries as lines of space-separated key=value pairs, as defined by the logfmt format.
+//
+// The elements timestamp, level, logger name, caller and message are written first, in this order, followed by the
+// fields of the log entry and the stack trace. Each of logger name, caller and stack trace is only written if it is
+// available. Fields whose keys equal the key of a built-in element are prefixed by "fields.", e.g. "fields.msg".
+// Values containing spaces, quotes, equal signs or control characters are quoted. The zero value is ready to use.
+type LogfmtFormatter struct {
+  TimeKey         string  // Key of the timestamp. Defaults to KEY_LOGFMT_TIME if empty.
+  LevelKey        string  // Key of the log level. Defaults to KEY_LEVEL if empty.
+  NameKey         string  // Key of the Logger name. Defaults to KEY_NAME if empty.
+  CallerKey       string  // Key of the caller. Defaults to KEY_CALLER if empty.
+  MessageKey      string  // Key of the log message. Defaults to KEY_MESSAGE if empty.
+  StackKey        string  // Key of the stack trace. Defaults to KEY_STACK if empty.
+  // Encoding of the timestamp. Supported encodings: TS_ENC_LAYOUT, TS_ENC_UNIX, TS_ENC_UNIX_MILLI and TS_ENC_UNIX_NANO.
+  // TS_ENC_LAYOUT uses the timestamp format of the Logger.
+  TimestampEncoding int
+}
+
+// NewLogfmtFormatter returns a new LogfmtFormatter object with default key names.
+func NewLogfmtFormatter() *LogfmtFormatter {
+  return &LogfmtFormatter{
+    TimeKey: KEY_LOGFMT_TIME,
+    LevelKey: KEY_LEVEL,
+    NameKey: KEY_NAME,
+    CallerKey: KEY_CALLER,
+    MessageKey: KEY_MESSAGE,
+    StackKey: KEY_STACK,
+    TimestampEncoding: TS_ENC_LAYOUT,
+  }
+}
+
+// Format writes the log entry as a single line of logfmt key=value pairs.
+func (f *LogfmtFormatter) Format(w io.Writer, entry *Entry) error {
+  var sb strings.Builder
+  add := func(key, value string) {
+    if sb.Len() > 0 { sb.WriteByte(' ') }
+    writeFieldPair(&sb, key, value)
+  }
+
+  if key := keyName(f.TimeKey, KEY_LOGFMT_TIME); key != KEY_OMIT {
+    add(key, string(encodeTimestamp(entry.Time, f.TimestampEncoding, entry.TimestampFormat, false)))
+  }
+  if key := keyName(f.LevelKey, KEY_LEVEL); key != KEY_OMIT {
+    add(key, getLevelName(entry.Level))
+  }
+  if key := keyName(f.NameKey, KEY_NAME); key != KEY_OMIT && len(entry.Name) > 0 {
+    add(key, entry.Name)
+  }
+  if key := keyName(f.CallerKey, KEY_CALLER); key != KEY_OMIT && entry.HasCaller() {
+    add(key, formatCaller(entry.Caller, entry.CallerFormat))
+  }
+  if key := keyName(f.MessageKey, KEY_MESSAGE); key != KEY_OMIT {
+    add(key, strings.TrimSuffix(entry.Message, "\n"))
+  }
+  builtin := []string{
+    keyName(f.TimeKey, KEY_LOGFMT_TIME), keyName(f.LevelKey, KEY_LEVEL), keyName(f.NameKey, KEY_NAME),
+    keyName(f.CallerKey, KEY_CALLER), keyName(f.MessageKey, KEY_MESSAGE), keyName(f.StackKey, KEY_STACK),
+  }
+  for _, field := range entry.Fields {
+    add(fieldKey(field.Key, builtin), formatFieldValue(field.Value))
+  }
+  if key := keyName(f.StackKey, KEY_STACK); key != KEY_OMIT && len(entry.Stack) > 0 {
+    add(key, entry.Stack)
+  }
+  sb.WriteByte('\n')
+
+  _, err := io.WriteString(w, sb.String())
+  return err
+}
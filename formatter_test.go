@@ -0,0 +1,103 @@
+package logging
+
+import (
+  "bytes"
+  "encoding/json"
+  "errors"
+  "strings"
+  "testing"
+  "time"
+)
+
+func TestJSONFormatter(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  l.SetPrefixCaller(true)
+  l.SetFormatter(&JSONFormatter{MessageKey: "message", TimestampEncoding: TS_ENC_UNIX_MILLI})
+
+  l.Named("auth").Infow("login <ok>", "user", 42, Err(errors.New("none")), Duration("took", time.Second))
+  l.With(PrefixOptions{}).Infoln("second")
+
+  lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+  if len(lines) != 2 {
+    t.Fatalf("expected 2 lines, got %q", buf.String())
+  }
+  var m map[string]interface{}
+  if err := json.Unmarshal([]byte(lines[0]), &m); err != nil {
+    t.Fatalf("invalid JSON %q: %v", lines[0], err)
+  }
+  for key, value := range map[string]interface{}{"level": "info", "logger": "auth", "message": "login <ok>",
+                                                 "user": 42.0, "error": "none", "took": "1s"} {
+    if m[key] != value {
+      t.Errorf("expected %s=%v, got %v", key, value, m[key])
+    }
+  }
+  if _, ok := m["time"].(float64); !ok {
+    t.Errorf("expected numeric timestamp, got %v", m["time"])
+  }
+  if _, ok := m["caller"].(string); !ok {
+    t.Errorf("expected caller, got %v", m["caller"])
+  }
+  if !strings.HasPrefix(lines[0], `{"time":`) {
+    t.Errorf("unexpected element order: %s", lines[0])
+  }
+
+  if err := json.Unmarshal([]byte(lines[1]), &m); err != nil {
+    t.Fatalf("invalid JSON %q: %v", lines[1], err)
+  }
+  if m["message"] != "second" {
+    t.Errorf("expected message without newline, got %q", m["message"])
+  }
+}
+
+func TestJSONFormatterKeys(t *testing.T) {
+  var buf bytes.Buffer
+  f := NewJSONFormatter()
+  f.TimeKey = KEY_OMIT
+  f.LevelKey = "severity"
+  fields := Fields{Any("n", nil), String("msg", "x"), Int("severity", 1), Int("level", 2), Int("stacktrace", 3)}
+  entry := Entry{Time: time.Now(), Level: WARN, Message: "text\n", Fields: fields}
+  if err := f.Format(&buf, &entry); err != nil {
+    t.Fatal(err)
+  }
+  // fields cannot override built-in elements
+  expected := `{"severity":"warn","msg":"text","n":null,"fields.msg":"x","fields.severity":1,"level":2,"fields.stacktrace":3}` + "\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+}
+
+func TestLogfmtFormatterKeys(t *testing.T) {
+  var buf bytes.Buffer
+  f := &LogfmtFormatter{TimeKey: KEY_OMIT}
+  entry := Entry{Time: time.Now(), Level: INFO, Message: "text\n", Fields: Fields{String("msg", "x"), Int("ts", 1)}}
+  if err := f.Format(&buf, &entry); err != nil {
+    t.Fatal(err)
+  }
+  if expected := "level=info msg=text fields.msg=x ts=1\n"; buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+}
+
+func TestLogfmtFormatter(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(WARN, &buf)
+  l.SetTimestampFormat(TS_FMT_DATE)
+  l.SetFormatter(NewLogfmtFormatter())
+  var logged time.Time
+  l.AddHook(HOOK_AFTER_WRITE, NewHook(func(entry *Entry) error {
+    logged = entry.Time
+    return nil
+  }))
+
+  l.Named("db").Warnw("query failed", "sql", `SELECT "a" FROM b`, "expr", "a=b", "lines", "one\ntwo",
+                     "plain", "value", "bad key", 1, "empty", "")
+
+  expected := "ts=" + logged.Format(TS_FMT_DATE) + ` level=warn logger=db msg="query failed" ` +
+              `sql="SELECT \"a\" FROM b" expr="a=b" lines="one\ntwo" plain=value bad_key=1 empty=""` + "\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+}
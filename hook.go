@@ -0,0 +1,125 @@
+package logging
+// Contains hooks which are invoked for log entries.
+
+import (
+  "errors"
+  "fmt"
+)
+
+// Available stages at which hooks are invoked.
+const (
+  // Invoke the hook before the log entry is written. The hook may modify the entry or prevent writing it.
+  HOOK_BEFORE_WRITE = iota
+  // Invoke the hook after the log entry has been written to the output channel.
+  HOOK_AFTER_WRITE
+)
+
+// ErrDropEntry can be returned by hooks of stage HOOK_BEFORE_WRITE to prevent the log entry from being written.
+var ErrDropEntry = errors.New("logging: drop log entry")
+
+// Hook is invoked for every log entry of the given levels that passes verbosity filtering.
+//
+// Levels returns the log levels the hook is registered for. A nil slice registers the hook for all levels.
+// Fire receives the complete log entry, including caller information. Hooks of stage HOOK_BEFORE_WRITE may modify
+// the entry, e.g. add fields or change the message. They may return ErrDropEntry to prevent the entry from being
+// written. Other errors are passed to the error handler of the Logger. Critical actions are performed regardless of
+// hooks. Fire is called by the goroutine which logs the entry. It must not retain the entry after the call returns.
+type Hook interface {
+  Levels() []Level
+  Fire(entry *Entry) error
+}
+
+// Used internally. A Hook which calls a function.
+type hookFunc struct {
+  levels  []Level
+  fn      func(entry *Entry) error
+}
+
+// Used internally. A registered hook.
+type hookEntry struct {
+  stage   int
+  hook    Hook
+  levels  map[Level]bool  // nil for all levels
+}
+
+// Used internally. A list of registered hooks. Lists are never modified after they have been assigned to a Logger.
+type hookList []hookEntry
+
+
+// NewHook returns a Hook which calls the given function for log entries of the given levels.
+// The hook is registered for all levels if no levels are specified.
+func NewHook(fn func(entry *Entry) error, levels ...Level) Hook {
+  return &hookFunc{levels: levels, fn: fn}
+}
+
+
+// AddHook registers a hook which is invoked for log entries at the given stage.
+//
+// Supported stages: HOOK_BEFORE_WRITE and HOOK_AFTER_WRITE. Hooks are invoked in the order of registration.
+// Hooks are shared by the Logger and all loggers derived from it. Unsupported stages are ignored.
+func (l *Logger) AddHook(stage int, hook Hook) {
+  if stage < HOOK_BEFORE_WRITE || stage > HOOK_AFTER_WRITE || hook == nil { return }
+  entry := hookEntry{stage: stage, hook: hook}
+  if levels := hook.Levels(); levels != nil {
+    entry.levels = make(map[Level]bool, len(levels))
+    for _, level := range levels {
+      entry.levels[level] = true
+    }
+  }
+  l.mutex.Lock()
+  defer l.mutex.Unlock()
+  hooks := make(hookList, 0, len(l.hooks) + 1)
+  l.hooks = append(append(hooks, l.hooks...), entry)
+}
+
+// Global logger: AddHook registers a hook which is invoked for log entries at the given stage.
+//
+// Supported stages: HOOK_BEFORE_WRITE and HOOK_AFTER_WRITE. Hooks are invoked in the order of registration.
+// Hooks are shared by the global Logger and all loggers derived from it. Unsupported stages are ignored.
+func AddHook(stage int, hook Hook) { Global().AddHook(stage, hook) }
+
+
+// ClearHooks removes all registered hooks.
+func (l *Logger) ClearHooks() {
+  l.mutex.Lock()
+  l.hooks = nil
+  l.mutex.Unlock()
+}
+
+// Global logger: ClearHooks removes all registered hooks.
+func ClearHooks() { Global().ClearHooks() }
+
+
+// Levels returns the log levels of the hook.
+func (h *hookFunc) Levels() []Level {
+  return h.levels
+}
+
+
+// Fire calls the hook function.
+func (h *hookFunc) Fire(entry *Entry) error {
+  return h.fn(entry)
+}
+
+
+// Used internally. Returns whether hooks are registered for the given log level.
+func (h hookList) registered(level Level) bool {
+  for _, entry := range h {
+    if entry.levels == nil || entry.levels[level] { return true }
+  }
+  return false
+}
+
+
+// Used internally. Invokes the hooks of the given stage. Returns false if a hook requests to drop the log entry.
+func (l *Logger) fireHooks(hooks hookList, stage int, entry *Entry) bool {
+  for _, h := range hooks {
+    if h.stage != stage || (h.levels != nil && !h.levels[entry.Level]) { continue }
+    if err := h.hook.Fire(entry); err == ErrDropEntry {
+      if stage == HOOK_BEFORE_WRITE { return false }
+    } else if err != nil {
+      l.handleError(fmt.Errorf("hook failed: %w", err), entry)
+    }
+  }
+  return true
+}
@@ -0,0 +1,93 @@
+package logging
+
+import (
+  "bytes"
+  "errors"
+  "io"
+  "os"
+  "strings"
+  "testing"
+)
+
+// Returns the data written to stderr by the given function.
+func captureStderr(t *testing.T, fn func()) string {
+  r, w, err := os.Pipe()
+  if err != nil { t.Fatal(err) }
+  stderr := os.Stderr
+  os.Stderr = w
+  defer func() { os.Stderr = stderr }()
+  fn()
+  w.Close()
+  data, _ := io.ReadAll(r)
+  r.Close()
+  return string(data)
+}
+
+func TestHooks(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(WARN, &buf)
+  l.SetOutput(ERROR, &buf)
+
+  var failures, written int
+  var last Entry
+  l.AddHook(HOOK_BEFORE_WRITE, NewHook(func(entry *Entry) error {
+    if strings.Contains(entry.Message, "secret") { return ErrDropEntry }
+    entry.Fields = append(entry.Fields, String("host", "web1"))
+    return nil
+  }))
+  l.AddHook(HOOK_AFTER_WRITE, NewHook(func(entry *Entry) error {
+    failures++
+    last = *entry
+    return nil
+  }, ERROR, CRITICAL))
+  l.AddHook(HOOK_AFTER_WRITE, NewHook(func(entry *Entry) error {
+    written = buf.Len()
+    return nil
+  }, WARN))
+
+  child := l.WithFields("id", 1)
+  child.Infoln("secret token")
+  child.Warnln("slow")
+  child.Errorw("failed", "code", 500)
+  child.Infoln("done")
+
+  expected := "slow id=1 host=web1\nfailed id=1 code=500 host=web1\ndone id=1 host=web1\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+  if failures != 1 || last.Message != "failed\n" || len(last.Fields) != 3 || !last.HasCaller() {
+    t.Errorf("unexpected entry in hook: %d calls, %+v", failures, last)
+  }
+  if written != len("slow id=1 host=web1\n") {
+    t.Errorf("after hook invoked before writing: %d", written)
+  }
+  if len(child.fields) != 1 {
+    t.Errorf("hook modified bound fields: %v", child.fields)
+  }
+
+  l.ClearHooks()
+  buf.Reset()
+  l.Infoln("secret")
+  if buf.String() != "secret\n" {
+    t.Errorf("hooks not cleared: %q", buf.String())
+  }
+}
+
+
+func TestHookErrors(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  l.AddHook(HOOK_BEFORE_WRITE, NewHook(func(entry *Entry) error {
+    return errors.New("pager unavailable")
+  }))
+  stderr := captureStderr(t, func() { l.Infoln("message") })
+  if buf.String() != "message\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+  if !strings.Contains(stderr, "hook failed: pager unavailable") {
+    t.Errorf("hook error not reported: %q", stderr)
+  }
+}
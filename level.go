@@ -0,0 +1,241 @@
+package logging
+// Contains the registry of log levels.
+
+import (
+  "encoding/json"
+  "fmt"
+  "io"
+  "os"
+  "sort"
+  "strconv"
+  "strings"
+  "sync"
+)
+
+// Level defines the importance of log messages. Levels with higher numeric values are more important.
+//
+// The predefined levels are spaced apart, so that custom levels can be registered in between.
+type Level int
+
+// LevelOptions defines the properties of a registered log level.
+type LevelOptions struct {
+  Name      string      // Display name of the level, e.g. "AUDIT". Must be unique.
+  ShortName string      // Short name used in log prefixes, usually four characters, e.g. "AUDT". Must be unique.
+  Output    io.Writer   // Default output channel. Defaults to os.Stdout for levels below WARN, os.Stderr otherwise.
+  Color     string      // Optional ANSI color sequence for terminal output, e.g. COLOR_CYAN or "\x1b[36m".
+}
+
+// Used internally. The registry of log levels.
+var levels = struct {
+  mutex   sync.RWMutex
+  options map[Level]LevelOptions
+  sorted  []Level     // registered levels in increasing order
+}{
+  options: map[Level]LevelOptions{
+    TRACE:    {Name: "TRACE", ShortName: "TRCE", Color: COLOR_GRAY},
+    LOG:      {Name: "LOG", ShortName: "LOG", Color: COLOR_CYAN},
+    INFO:     {Name: "INFO", ShortName: "INFO", Color: COLOR_GREEN},
+    NOTICE:   {Name: "NOTICE", ShortName: "NOTE", Color: COLOR_BLUE},
+    WARN:     {Name: "WARN", ShortName: "WARN", Color: COLOR_YELLOW},
+    ERROR:    {Name: "ERROR", ShortName: "ERRO", Color: COLOR_RED},
+    CRITICAL: {Name: "CRITICAL", ShortName: "CRIT", Color: COLOR_BOLD_RED},
+    FATAL:    {Name: "FATAL", ShortName: "FATL", Color: COLOR_ALERT},
+  },
+  sorted: []Level{TRACE, LOG, INFO, NOTICE, WARN, ERROR, CRITICAL, FATAL},
+}
+
+// Used internally. Additional names of predefined levels accepted by ParseLevel. Registered names take precedence.
+var levelAliases = map[string]Level{
+  "DEBUG": DEBUG,
+}
+
+
+// RegisterLevel adds a new log level to the registry or updates the properties of an existing log level.
+//
+// Registered levels can be used with all Logger functions that take a level argument, such as SetVerbosity, SetOutput
+// and Printf. Name and short name are required and must not be used by other levels. Names are case-insensitive.
+func RegisterLevel(level Level, options LevelOptions) error {
+  if len(options.Name) == 0 || len(options.ShortName) == 0 {
+    return fmt.Errorf("logging: missing name of level %d", int(level))
+  }
+  levels.mutex.Lock()
+  defer levels.mutex.Unlock()
+  for lvl, opts := range levels.options {
+    if lvl != level && (strings.EqualFold(opts.Name, options.Name) || strings.EqualFold(opts.ShortName, options.ShortName)) {
+      return fmt.Errorf("logging: name of level %d already used by level %d", int(level), int(lvl))
+    }
+  }
+  if _, ok := levels.options[level]; !ok {
+    levels.sorted = append(levels.sorted, level)
+    sort.Slice(levels.sorted, func(i, j int) bool { return levels.sorted[i] < levels.sorted[j] })
+  }
+  levels.options[level] = options
+  return nil
+}
+
+
+// SetLevelColor defines the ANSI color sequence of a registered log level, e.g. COLOR_MAGENTA or "\x1b[38;5;208m".
+// Specify an empty string to print the level without color.
+func SetLevelColor(level Level, color string) error {
+  levels.mutex.Lock()
+  defer levels.mutex.Unlock()
+  opts, ok := levels.options[level]
+  if !ok { return fmt.Errorf("logging: level %d is not registered", int(level)) }
+  opts.Color = color
+  levels.options[level] = opts
+  return nil
+}
+
+
+// Levels returns all registered log levels in increasing order of importance.
+func Levels() []Level {
+  levels.mutex.RLock()
+  defer levels.mutex.RUnlock()
+  return append([]Level(nil), levels.sorted...)
+}
+
+
+// ParseLevel returns the log level of the given name, short name or number. Names are case-insensitive.
+// Numbers are accepted for unregistered levels as well. The name "DEBUG" is accepted as alias of LOG.
+func ParseLevel(s string) (Level, error) {
+  s = strings.TrimSpace(s)
+  if n, err := strconv.Atoi(s); err == nil { return Level(n), nil }
+  levels.mutex.RLock()
+  defer levels.mutex.RUnlock()
+  for _, lvl := range levels.sorted {
+    opts := levels.options[lvl]
+    if strings.EqualFold(opts.Name, s) || strings.EqualFold(opts.ShortName, s) { return lvl, nil }
+  }
+  if lvl, ok := levelAliases[strings.ToUpper(s)]; ok { return lvl, nil }
+  return 0, fmt.Errorf("logging: unknown level %q", s)
+}
+
+
+// IsRegistered returns whether the log level is registered.
+func (l Level) IsRegistered() bool {
+  _, ok := l.options()
+  return ok
+}
+
+
+// Name returns the display name of the log level. Unregistered levels are named "LEVEL(n)".
+func (l Level) Name() string {
+  if opts, ok := l.options(); ok { return opts.Name }
+  return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+
+// ShortName returns the short name of the log level. Unregistered levels are named "L(n)".
+func (l Level) ShortName() string {
+  if opts, ok := l.options(); ok { return opts.ShortName }
+  return fmt.Sprintf("L(%d)", int(l))
+}
+
+
+// Color returns the ANSI color sequence of the log level. Returns an empty string if no color is defined.
+func (l Level) Color() string {
+  opts, _ := l.options()
+  return opts.Color
+}
+
+
+// DefaultOutput returns the default output channel of the log level.
+func (l Level) DefaultOutput() io.Writer {
+  if opts, ok := l.options(); ok && opts.Output != nil { return opts.Output }
+  if l < WARN { return os.Stdout }
+  return os.Stderr
+}
+
+
+// String returns the display name of the log level.
+func (l Level) String() string {
+  return l.Name()
+}
+
+
+// Set parses the given name, short name or number and assigns the log level. Implements the flag.Value interface.
+func (l *Level) Set(s string) error {
+  level, err := ParseLevel(s)
+  if err != nil { return err }
+  *l = level
+  return nil
+}
+
+
+// MarshalText returns the display name of the log level, or its number if the level is not registered.
+func (l Level) MarshalText() ([]byte, error) {
+  if opts, ok := l.options(); ok { return []byte(opts.Name), nil }
+  return []byte(strconv.Itoa(int(l))), nil
+}
+
+
+// UnmarshalText parses the given name, short name or number and assigns the log level.
+func (l *Level) UnmarshalText(text []byte) error {
+  return l.Set(string(text))
+}
+
+
+// MarshalJSON returns the display name of the log level as JSON string, or its number if the level is not registered.
+func (l Level) MarshalJSON() ([]byte, error) {
+  if opts, ok := l.options(); ok { return json.Marshal(opts.Name) }
+  return []byte(strconv.Itoa(int(l))), nil
+}
+
+
+// UnmarshalJSON parses a JSON string containing a name, short name or number, or a JSON number, and assigns
+// the log level.
+func (l *Level) UnmarshalJSON(data []byte) error {
+  var s string
+  if err := json.Unmarshal(data, &s); err != nil {
+    var n int
+    if json.Unmarshal(data, &n) != nil { return fmt.Errorf("logging: invalid level %s", data) }
+    *l = Level(n)
+    return nil
+  }
+  return l.Set(s)
+}
+
+
+// Used internally. Returns the properties of the log level and whether the level is registered.
+func (l Level) options() (LevelOptions, bool) {
+  levels.mutex.RLock()
+  defer levels.mutex.RUnlock()
+  opts, ok := levels.options[l]
+  return opts, ok
+}
+
+
+// Used internally. Returns the next registered level above (dir > 0) or below (dir < 0) the given level.
+// Returns the given level if no such level exists.
+func adjacentLevel(level Level, dir int) Level {
+  levels.mutex.RLock()
+  defer levels.mutex.RUnlock()
+  if dir > 0 {
+    for _, lvl := range levels.sorted {
+      if lvl > level { return lvl }
+    }
+  } else {
+    for i := len(levels.sorted) - 1; i >= 0; i-- {
+      if levels.sorted[i] < level { return levels.sorted[i] }
+    }
+  }
+  return level
+}
+
+
+// Used internally. Returns the lowest registered level.
+func levelMin() Level {
+  levels.mutex.RLock()
+  defer levels.mutex.RUnlock()
+  return levels.sorted[0]
+}
+
+
+// Used internally. Restricts the level to the range of registered levels.
+func clampLevel(level Level) Level {
+  levels.mutex.RLock()
+  defer levels.mutex.RUnlock()
+  if level < levels.sorted[0] { return levels.sorted[0] }
+  if last := levels.sorted[len(levels.sorted)-1]; level > last { return last }
+  return level
+}
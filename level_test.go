@@ -0,0 +1,127 @@
+package logging
+
+import (
+  "bytes"
+  "encoding/json"
+  "flag"
+  "testing"
+)
+
+func TestCustomLevels(t *testing.T) {
+  var audit bytes.Buffer
+  const AUDIT Level = 25
+  if err := RegisterLevel(AUDIT, LevelOptions{Name: "AUDIT", ShortName: "AUDT", Output: &audit}); err != nil {
+    t.Fatal(err)
+  }
+  if err := RegisterLevel(26, LevelOptions{Name: "audit", ShortName: "AUD2"}); err == nil {
+    t.Error("expected error for duplicate level name")
+  }
+
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(TRACE, &buf)
+  l.SetOutput(NOTICE, &buf)
+
+  l.Printf(AUDIT, "user %s logged in\n", "admin")
+  l.Println(NOTICE, "notice")
+  l.Printw(TRACE, "filtered")
+  l.SetVerbosity(TRACE)
+  l.Printw(TRACE, "trace", "n", 1)
+
+  if audit.String() != "AUDT user admin logged in\n" {
+    t.Errorf("unexpected output of custom level: %q", audit.String())
+  }
+  if buf.String() != "NOTE notice\nTRCE trace n=1\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+
+  l.SetVerbosity(WARN)
+  if level := l.IncreaseVerbosity(); level != AUDIT {
+    t.Errorf("expected verbosity %s, got %s", AUDIT.Name(), level.Name())
+  }
+  l.Printf(AUDIT, "visible\n")
+  l.Warn("invisible\n")
+  if l.IncreaseVerbosity() != ERROR || l.DecreaseVerbosity() != AUDIT {
+    t.Error("unexpected verbosity order")
+  }
+  l.SetVerbosity(1000)
+  if l.GetVerbosity() != FATAL {
+    t.Errorf("expected verbosity to be clamped to FATAL, got %s", l.GetVerbosity().Name())
+  }
+  if audit.String() != "AUDT user admin logged in\nAUDT visible\n" {
+    t.Errorf("unexpected output of custom level: %q", audit.String())
+  }
+  if Level(33).Name() != "LEVEL(33)" || Level(33).IsRegistered() {
+    t.Error("unexpected properties of unregistered level")
+  }
+}
+
+
+func TestParseLevel(t *testing.T) {
+  tests := map[string]Level{"warn": WARN, "ERRO": ERROR, " Critical ": CRITICAL, "20": WARN, "-3": Level(-3), "debug": DEBUG, "DEBUG": LOG}
+  for s, expected := range tests {
+    if level, err := ParseLevel(s); err != nil || level != expected {
+      t.Errorf("ParseLevel(%q): expected %v, got %v (%v)", s, expected, level, err)
+    }
+  }
+  if _, err := ParseLevel("verbose"); err == nil {
+    t.Error("expected error for unknown level")
+  }
+
+  var config struct {
+    Level   Level
+    Levels  []Level
+  }
+  if err := json.Unmarshal([]byte(`{"Level":"note","Levels":["info",30,"-7","debug"]}`), &config); err != nil {
+    t.Fatal(err)
+  }
+  if config.Level != NOTICE || len(config.Levels) != 4 || config.Levels[3] != LOG || config.Levels[1] != ERROR || config.Levels[2] != Level(-7) {
+    t.Errorf("unexpected result of UnmarshalJSON: %v", config)
+  }
+  data, err := json.Marshal(config)
+  if err != nil { t.Fatal(err) }
+  if string(data) != `{"Level":"NOTICE","Levels":["INFO","ERROR",-7,"LOG"]}` {
+    t.Errorf("unexpected result of MarshalJSON: %s", data)
+  }
+  if text, _ := FATAL.MarshalText(); string(text) != "FATAL" || FATAL.String() != "FATAL" {
+    t.Errorf("unexpected text of level FATAL: %s", text)
+  }
+}
+
+
+func TestVerbosityFlags(t *testing.T) {
+  l := NewLogger()
+  fs := flag.NewFlagSet("test", flag.ContinueOnError)
+  l.VerbosityFlag(fs, "level", "verbosity level")
+  l.VerbosityFlags(fs, "v", "q")
+  l.VModuleFlag(fs, "vmodule", "verbosity rules")
+
+  if err := fs.Parse([]string{"-level", "warn", "-v", "-v", "-v", "-q"}); err != nil {
+    t.Fatal(err)
+  }
+  if l.GetVerbosity() != INFO {
+    t.Errorf("expected verbosity INFO, got %v", l.GetVerbosity())
+  }
+  if err := fs.Parse([]string{"-level=ERRO", "-v=false", "-vmodule=db/*=trce"}); err != nil {
+    t.Fatal(err)
+  }
+  if l.GetVModule() != "db/*=TRACE" {
+    t.Errorf("unexpected vmodule spec: %q", l.GetVModule())
+  }
+  if l.GetVerbosity() != ERROR {
+    t.Errorf("expected verbosity ERROR, got %v", l.GetVerbosity())
+  }
+  if err := fs.Parse([]string{"-level=debug"}); err != nil || l.GetVerbosity() != LOG {
+    t.Errorf("expected verbosity LOG, got %v (%v)", l.GetVerbosity(), err)
+  }
+  if err := fs.Parse([]string{"-level=loud"}); err == nil {
+    t.Error("expected error for unknown level")
+  }
+
+  var level Level = INFO
+  fs.Var(&level, "threshold", "threshold level")
+  if err := fs.Parse([]string{"-threshold=crit"}); err != nil || level != CRITICAL {
+    t.Errorf("expected level CRITICAL, got %v (%v)", level, err)
+  }
+}
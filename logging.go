@@ -4,26 +4,36 @@ Package logging provides functionality for printing log messages at various impo
 package logging
 
 import (
+  "bytes"
   "fmt"
   "io"
-  "os"
   "runtime"
   "strings"
+  "sync"
   "time"
 )
 
-// Available verbosity levels.
+// Available verbosity levels. Additional levels can be defined by RegisterLevel.
 const (
+  // Print TRACE or higher priority messages. TRACE is directed to Stdout by default.
+  TRACE     Level = -10
   // Print LOG or higher priority messages. LOG is directed to Stdout by default.
-  LOG = iota
+  LOG       Level = 0
   // Print only INFO or higher priority messages. INFO is directed to Stdout by default. This is the default verbosity level.
-  INFO
+  INFO      Level = 10
+  // Print NOTICE or higher priority messages. NOTICE is directed to Stdout by default.
+  NOTICE    Level = 15
   // Print WARN or higher priority messages. WARN is directed to Stderr by default.
-  WARN
+  WARN      Level = 20
   // Print ERROR or higher priority messages. ERROR is directed to Stderr by default.
-  ERROR
+  ERROR     Level = 30
   // Set this verbosity level to print only critical messages. CRITICAL is directed to Stderr by default.
-  CRITICAL
+  CRITICAL  Level = 40
+  // Set this verbosity level to print only fatal messages. FATAL is directed to Stderr by default.
+  FATAL     Level = 50
+
+  // DEBUG is an alias for LOG.
+  DEBUG     Level = LOG
 )
 
 // A set of predefined timestamp formats. You can also use layouts from the Golang package "time".
@@ -44,16 +54,71 @@ const (
   TS_FMT_DATETIME_TZ_MICRO  = "2006-01-02 15:04:05.000000-0700"
 )
 
-type outputMap  map[int]io.Writer
+type outputMap  map[Level]io.Writer
+
+// EntryWriter is implemented by output channels which process log entries directly instead of formatted data.
+//
+// WriteEntry is called instead of Write for every log entry sent to the output channel. The Formatter of the
+// Logger is not used. The entry must not be retained after the call returns.
+type EntryWriter interface {
+  io.Writer
+  WriteEntry(entry *Entry) error
+}
+
+// LevelWriter is implemented by output channels which handle log entries differently depending on their log level.
+//
+// WriteLevel is called instead of Write for every log entry sent to the output channel.
+type LevelWriter interface {
+  io.Writer
+  WriteLevel(level Level, p []byte) (n int, err error)
+}
 
+// PrefixOptions defines the visibility of the individual log prefix components.
+type PrefixOptions struct {
+  Timestamp bool  // Prefix log messages by the current timestamp
+  Caller    bool  // Prefix log messages by name and line number of the calling function
+  Level     bool  // Prefix log messages by a symbolic name of their level
+}
+
+// Logger is safe for concurrent use by multiple goroutines.
 type Logger struct {
-  verbosity     int
-  output        outputMap
-  overrideStack []bool
-  prefixTS      bool
-  prefixLevel   bool
-  prefixCaller  bool
-  fmtTimestamp  string
+  *loggerState                // shared by all loggers derived from the same root Logger
+  prefix        *PrefixOptions  // overrides the prefix settings of the shared state if defined
+  name          string          // dotted name of the Logger, added to the log prefix if defined
+  fields        Fields          // bound fields, added to all log entries
+  callerSkip    int             // number of additional stack frames skipped to determine the caller
+}
+
+// Used internally. Contains the settings shared by a Logger and all loggers derived from it.
+type loggerState struct {
+  mutex             sync.RWMutex  // guards all settings below
+  writeMutex        sync.Mutex    // serializes writing log entries to the output channels
+  verbosity         Level
+  vmodule           *vmoduleRules  // per-package and per-file verbosity rules, nil if undefined
+  sampling          map[Level]*sampler  // rate limits and sampling per log level
+  collapseTimeout   time.Duration  // collapsing of repeated messages is disabled if 0
+  repeats           map[io.Writer]*repeatState  // most recent log entry per output channel, guarded by writeMutex
+  stackTrace        *StackTraceOptions  // stack traces are disabled if nil
+  output            outputMap
+  prefixTS          bool
+  prefixLevel       bool
+  prefixCaller      bool
+  fmtTimestamp      string
+  callerFormat      int
+  formatter         Formatter
+  colorMode         int
+  colorResolved     int           // color mode as resolved by the environment, see colorEnv
+  terminals         *sync.Map     // caches whether output files are terminals, replaced when outputs are changed
+  hooks             hookList
+  sinks             []Sink
+  errorHandler      func(err error, entry *Entry)
+  fallback          io.Writer
+  retryAttempts     int
+  retryBackoff      time.Duration
+  stats             writeStats    // guarded by its own mutex
+  criticalAction    int
+  exitCode          int
+  criticalCallback  func(entry *Entry)
 }
 
 var (
@@ -64,20 +129,21 @@ var (
 
 // NewLogger returns a new logger object.
 func NewLogger() *Logger {
-  l := Logger{
+  l := Logger{loggerState: &loggerState{
     verbosity: INFO,    // Setting reasonable default log level
-    output: make(outputMap),  // Maps log levels to Writer objects, such as os.Stdout or a file
-    overrideStack: make([]bool, 0, 8),  // Temporarily stores current prefix visibility settings
+    output: make(outputMap),  // Maps log levels to Writer objects, such as os.Stdout or a file. Defaults are defined by the level registry.
     prefixTS: false,
     prefixLevel: false,
     prefixCaller: false,
     fmtTimestamp: TS_FMT_TIME_MILLI,
-  }
-  l.output[LOG]       = os.Stdout
-  l.output[INFO]      = os.Stdout
-  l.output[WARN]      = os.Stderr
-  l.output[ERROR]     = os.Stderr
-  l.output[CRITICAL]  = os.Stderr
+    callerFormat: CALLER_FUNCTION,
+    formatter: NewTextFormatter(),
+    colorMode: COLOR_NEVER,
+    colorResolved: COLOR_NEVER,
+    terminals: new(sync.Map),
+    criticalAction: CRITICAL_PANIC,
+    exitCode: 1,
+  }}
   return &l
 }
 
@@ -91,58 +157,70 @@ func Global() *Logger {
 
 // GetVerbosity returns the current verbosity level.
 // Only log messages of the current verbosity level or higher will be logged.
-func (l *Logger) GetVerbosity() int {
+func (l *Logger) GetVerbosity() Level {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
   return l.verbosity
 }
 
 // Global logger: GetVerbosity returns the current verbosity level.
 // Only log messages of the current verbosity level or higher will be logged.
-func GetVerbosity() int { return Global().GetVerbosity() }
+func GetVerbosity() Level { return Global().GetVerbosity() }
 
 
 // SetVerbosity sets the current verbosity level.
 //
 // Log messages of the current verbosity level or higher will be logged.
-// Supported levels in increasing order of importance: LOG, INFO, WARN, ERROR and CRITICAL.
-func (l *Logger) SetVerbosity(level int) {
-  if level < LOG { level = LOG }
-  if level > CRITICAL { level = CRITICAL }
+// Predefined levels in increasing order of importance: TRACE, LOG, INFO, NOTICE, WARN, ERROR, CRITICAL and FATAL.
+// The level is restricted to the range of registered levels.
+func (l *Logger) SetVerbosity(level Level) {
+  level = clampLevel(level)
+  l.mutex.Lock()
   l.verbosity = level
+  l.mutex.Unlock()
 }
 
 // Global logger: SetVerbosity sets the current verbosity level.
 //
 // Log messages of the current verbosity level or higher will be logged.
-// Supported levels in increasing order of importance: LOG, INFO, WARN, ERROR and CRITICAL.
-func SetVerbosity(level int) { Global().SetVerbosity(level) }
+// Predefined levels in increasing order of importance: TRACE, LOG, INFO, NOTICE, WARN, ERROR, CRITICAL and FATAL.
+// The level is restricted to the range of registered levels.
+func SetVerbosity(level Level) { Global().SetVerbosity(level) }
 
 
-// IncreaseVerbosity increases the current verbosity by one level.
-// Does nothing if highest level "CRITICAL" is already set. Returns the new verbosity level.
-func (l *Logger) IncreaseVerbosity() int {
-  if l.verbosity < CRITICAL { l.verbosity++ }
+// IncreaseVerbosity increases the current verbosity to the next higher registered level.
+// Does nothing if the highest level is already set. Returns the new verbosity level.
+func (l *Logger) IncreaseVerbosity() Level {
+  l.mutex.Lock()
+  defer l.mutex.Unlock()
+  l.verbosity = adjacentLevel(l.verbosity, 1)
   return l.verbosity
 }
 
-// Global logger: IncreaseVerbosity increases the current verbosity by one level.
-// Does nothing if highest level "CRITICAL" is already set. Returns the new verbosity level.
-func IncreaseVerbosity() int { return Global().IncreaseVerbosity() }
+// Global logger: IncreaseVerbosity increases the current verbosity to the next higher registered level.
+// Does nothing if the highest level is already set. Returns the new verbosity level.
+func IncreaseVerbosity() Level { return Global().IncreaseVerbosity() }
 
 
-// DecreaseVerbosity decreases the current verbosity by one level.
-// Does nothing if lowest level "LOG" is already set. Returns the new verbosity level.
-func (l *Logger) DecreaseVerbosity() int {
-  if l.verbosity > LOG { l.verbosity-- }
+// DecreaseVerbosity decreases the current verbosity to the next lower registered level.
+// Does nothing if the lowest level is already set. Returns the new verbosity level.
+func (l *Logger) DecreaseVerbosity() Level {
+  l.mutex.Lock()
+  defer l.mutex.Unlock()
+  l.verbosity = adjacentLevel(l.verbosity, -1)
   return l.verbosity
 }
 
-// Global logger: DecreaseVerbosity decreases the current verbosity by one level.
-// Does nothing if lowest level "LOG" is already set. Returns the new verbosity level.
-func DecreaseVerbosity() int { return Global().DecreaseVerbosity() }
+// Global logger: DecreaseVerbosity decreases the current verbosity to the next lower registered level.
+// Does nothing if the lowest level is already set. Returns the new verbosity level.
+func DecreaseVerbosity() Level { return Global().DecreaseVerbosity() }
 
 
 // GetPrefixTimestamp returns whether log messages are prefixed by the current timestamp.
 func (l *Logger) GetPrefixTimestamp() bool {
+  if l.prefix != nil { return l.prefix.Timestamp }
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
   return l.prefixTS
 }
 
@@ -152,7 +230,9 @@ func GetPrefixTimestamp() bool { return Global().GetPrefixTimestamp() }
 
 // SetPrefixTimestamp defines whether log messages should be prefixed by the current timestamp.
 func (l *Logger) SetPrefixTimestamp(set bool) {
+  l.mutex.Lock()
   l.prefixTS = set
+  l.mutex.Unlock()
 }
 
 // Global logger: SetPrefixTimestamp defines whether log messages should be prefixed by the current timestamp.
@@ -161,6 +241,8 @@ func SetPrefixTimestamp(set bool) { Global().SetPrefixTimestamp(set) }
 
 // GetTimestampFormat returns the format string for the timestamp prefix.
 func (l *Logger) GetTimestampFormat() string {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
   return l.fmtTimestamp
 }
 
@@ -173,7 +255,9 @@ func GetTimestampFormat() string { return Global().GetTimestampFormat() }
 // Use either the TS_FMT_xxx constants, predefined constants from Golang's time package
 // or define a custom format on your own. Format description: https://golang.org/pkg/time/#pkg-constants
 func (l *Logger) SetTimestampFormat(format string) {
+  l.mutex.Lock()
   l.fmtTimestamp = format
+  l.mutex.Unlock()
 }
 
 // Global logger: SetTimestampFormat sets a new format string for the timestamp prefix.
@@ -185,6 +269,9 @@ func SetTimestampFormat(format string) { Global().SetTimestampFormat(format) }
 
 // GetPrefixCaller returns whether log messags are prefixed by name and line number of the calling function.
 func (l *Logger) GetPrefixCaller() bool {
+  if l.prefix != nil { return l.prefix.Caller }
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
   return l.prefixCaller
 }
 
@@ -194,7 +281,9 @@ func GetPrefixCaller() bool { return Global().GetPrefixCaller() }
 
 // SetPrefixCaller defines whether log messages should be prefixed by name and line number of the calling function.
 func (l *Logger) SetPrefixCaller(set bool) {
+  l.mutex.Lock()
   l.prefixCaller = set
+  l.mutex.Unlock()
 }
 
 // Global logger: SetPrefixCaller defines whether log messages should be prefixed by name and line number of the calling function.
@@ -203,6 +292,9 @@ func SetPrefixCaller(set bool) { Global().SetPrefixCaller(set) }
 
 // GetPrefixLevel returns whether log messages are prefixed by a symbolic name of their level.
 func (l *Logger) GetPrefixLevel() bool {
+  if l.prefix != nil { return l.prefix.Level }
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
   return l.prefixLevel
 }
 
@@ -212,80 +304,203 @@ func GetPrefixLevel() bool { return Global().GetPrefixLevel() }
 
 // SetPrefixLevel defines whether log messages should be prefixed by a symbolic name of their level.
 func (l *Logger) SetPrefixLevel(set bool) {
+  l.mutex.Lock()
   l.prefixLevel = set
+  l.mutex.Unlock()
 }
 
 // Global logger: SetPrefixLevel defines whether log messages should be prefixed by a symbolic name of their level.
 func SetPrefixLevel(set bool) { Global().SetPrefixLevel(set) }
 
 
+// GetFormatter returns the Formatter object which defines the layout of log entries.
+func (l *Logger) GetFormatter() Formatter {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.formatter
+}
+
+// Global logger: GetFormatter returns the Formatter object which defines the layout of log entries.
+func GetFormatter() Formatter { return Global().GetFormatter() }
+
+
+// SetFormatter defines the Formatter object which defines the layout of log entries.
+//
+// By default log entries are formatted by a TextFormatter. Specify a nil Formatter to restore the default layout.
+func (l *Logger) SetFormatter(formatter Formatter) {
+  if formatter == nil { formatter = NewTextFormatter() }
+  l.mutex.Lock()
+  l.formatter = formatter
+  l.mutex.Unlock()
+}
+
+// Global logger: SetFormatter defines the Formatter object which defines the layout of log entries.
+//
+// By default log entries are formatted by a TextFormatter. Specify a nil Formatter to restore the default layout.
+func SetFormatter(formatter Formatter) { Global().SetFormatter(formatter) }
+
+
 // GetOutput returns the Writer object for messages of the given level.
 //
-// By default LOG and INFO are written to os.Stdout. WARN, ERROR and CRITICAL are written to os.Stderr.
-// Returns nil for unsupported log levels.
-func (l *Logger) GetOutput(level int) io.Writer {
-  if level < LOG || level > CRITICAL { return nil }
-  return l.output[level]
+// By default TRACE, LOG, INFO and NOTICE are written to os.Stdout. WARN, ERROR, CRITICAL and FATAL are written to
+// os.Stderr. Default output channels of custom levels are defined by RegisterLevel.
+func (l *Logger) GetOutput(level Level) io.Writer {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return l.getOutput(level)
 }
 
 // Global logger: GetOutput returns the Writer object for messages of the given level.
 //
-// By default LOG and INFO are written to os.Stdout. WARN, ERROR and CRITICAL are written to os.Stderr.
-// Returns nil for unsupported log levels.
-func GetOutput(level int) io.Writer { return Global().GetOutput(level) }
+// By default TRACE, LOG, INFO and NOTICE are written to os.Stdout. WARN, ERROR, CRITICAL and FATAL are written to
+// os.Stderr. Default output channels of custom levels are defined by RegisterLevel.
+func GetOutput(level Level) io.Writer { return Global().GetOutput(level) }
 
 
 // SetOutput redirects log messages of the given level to the specified Writer object.
 //
-// By default LOG and INFO are written to os.Stdout. WARN, ERROR and CRITICAL are written to os.Stderr.
-// Unsupported log levels are ignored. Specify a nil Writer to restore default output channel for the given level.
+// By default TRACE, LOG, INFO and NOTICE are written to os.Stdout. WARN, ERROR, CRITICAL and FATAL are written to
+// os.Stderr. Default output channels of custom levels are defined by RegisterLevel. Specify a nil Writer to restore
+// the default output channel for the given level. If sinks are defined, the output channels of the log levels are
+// only used by sinks without Writer, see SetSinks.
 // The caller is responsible to close the specified Writer after it is no longer used.
-func (l *Logger) SetOutput(level int, writer io.Writer) {
-  if level < LOG || level > CRITICAL { return }
+func (l *Logger) SetOutput(level Level, writer io.Writer) {
+  l.mutex.Lock()
   if writer == nil {
-    switch level {
-      case WARN:
-      case ERROR:
-      case CRITICAL:
-        writer = os.Stderr
-      default:
-        writer = os.Stdout
-    }
+    delete(l.output, level)
+  } else {
+    l.output[level] = writer
   }
-  l.output[level] = writer
+  l.terminals = new(sync.Map)
+  l.mutex.Unlock()
 }
 
 // Global logger: SetOutput redirects log messages of the given level to the specified Writer object.
 //
-// By default LOG and INFO are written to os.Stdout. WARN, ERROR and CRITICAL are written to os.Stderr.
-// Unsupported log levels are ignored. Specify a nil Writer to restore default output channel for the given level.
+// By default TRACE, LOG, INFO and NOTICE are written to os.Stdout. WARN, ERROR, CRITICAL and FATAL are written to
+// os.Stderr. Default output channels of custom levels are defined by RegisterLevel. Specify a nil Writer to restore
+// the default output channel for the given level. If sinks are defined, the output channels of the log levels are
+// only used by sinks without Writer, see SetSinks.
 // The caller is responsible to close the specified Writer after it is no longer used.
-func SetOutput(level int, writer io.Writer) { Global().SetOutput(level, writer) }
+func SetOutput(level Level, writer io.Writer) { Global().SetOutput(level, writer) }
+
 
+// With returns a Logger that uses the specified prefix options instead of the current log prefix settings.
+//
+// The returned Logger shares all other settings, such as verbosity and output channels, with the original Logger.
+// Prefix settings of the original Logger are never modified and changing them has no effect on the returned Logger.
+func (l *Logger) With(options PrefixOptions) *Logger {
+  child := l.clone()
+  child.prefix = &options
+  return child
+}
 
-// OverridePrefix overrides current log prefix settings only for the next call of a log output function. 
+// Global logger: With returns a Logger that uses the specified prefix options instead of the current log prefix settings.
 //
-// Log output functions are: Log/Logf/Logln, Info/Infof/Infoln, Warn/Warnf/Warnln, Error/Errorf/Errorln and
-// Critical/Criticalf/Criticalln. Returns the Logger object to allow chaining function calls.
+// The returned Logger shares all other settings, such as verbosity and output channels, with the global Logger.
+// Prefix settings of the global Logger are never modified and changing them has no effect on the returned Logger.
+func With(options PrefixOptions) *Logger { return Global().With(options) }
+
+
+// Named returns a child Logger with the given name appended to the dotted name of the current Logger.
 //
-// Important: Multiple calls of this function are cumulative.
+// The name is added to the log prefix of all messages. The child Logger shares all settings, such as verbosity
+// and output channels, with the current Logger. Changes to these settings are visible to both loggers.
+func (l *Logger) Named(name string) *Logger {
+  child := l.clone()
+  if len(name) > 0 {
+    if len(child.name) > 0 {
+      child.name = child.name + "." + name
+    } else {
+      child.name = name
+    }
+  }
+  return child
+}
+
+// Global logger: Named returns a child Logger with the given name appended to the dotted name of the global Logger.
+//
+// The name is added to the log prefix of all messages. The child Logger shares all settings, such as verbosity
+// and output channels, with the global Logger. Changes to these settings are visible to both loggers.
+func Named(name string) *Logger { return Global().Named(name) }
+
+
+// GetName returns the dotted name of the Logger. Returns an empty string if the Logger is unnamed.
+func (l *Logger) GetName() string {
+  return l.name
+}
+
+// Global logger: GetName returns the dotted name of the global Logger, which is usually empty.
+func GetName() string { return Global().GetName() }
+
+
+// WithFields returns a child Logger that adds the given key/value pairs to every log entry.
+//
+// Fields are specified as alternating keys and values or as Field objects. They are added to the fields inherited
+// from the current Logger. The child Logger shares all settings, such as verbosity and output channels, with the
+// current Logger. Changes to these settings are visible to both loggers.
+func (l *Logger) WithFields(keysAndValues ...interface{}) *Logger {
+  child := l.clone()
+  child.fields = appendFields(l.fields, makeFields(keysAndValues))
+  return child
+}
+
+// Global logger: WithFields returns a child Logger that adds the given key/value pairs to every log entry.
+//
+// Fields are specified as alternating keys and values or as Field objects. The child Logger shares all settings,
+// such as verbosity and output channels, with the global Logger. Changes to these settings are visible to both loggers.
+func WithFields(keysAndValues ...interface{}) *Logger { return Global().WithFields(keysAndValues...) }
+
+
+// Flush writes pending log entries of all output channels which buffer data, such as AsyncWriter.
+//
+// Summaries of suppressed and repeated log entries are written before the output channels are flushed.
+// Output channels are flushed if they provide a method "Flush() error". Returns the first error encountered.
+func (l *Logger) Flush() error {
+  l.flushSampling()
+  l.flushRepeats()
+  l.mutex.RLock()
+  var writers []io.Writer
+  for _, level := range Levels() {
+    writers = append(writers, l.getOutput(level))
+  }
+  for _, sink := range l.sinks {
+    if sink.Writer != nil { writers = append(writers, sink.Writer) }
+  }
+  l.mutex.RUnlock()
+  return flushWriters(writers)
+}
+
+// Global logger: Flush writes pending log entries of all output channels which buffer data, such as AsyncWriter.
+//
+// Summaries of suppressed and repeated log entries are written before the output channels are flushed.
+// Output channels are flushed if they provide a method "Flush() error". Returns the first error encountered.
+func Flush() error { return Global().Flush() }
+
+
+// OverridePrefix returns a Logger that uses the specified prefix settings instead of the current log prefix settings.
+//
+// It allows to override prefix settings for a single call of a log output function by chaining function calls, e.g.
+// l.OverridePrefix(false, false, false).Infoln("Message"). The original Logger is not modified.
+//
+// Deprecated: Use With instead.
 func (l *Logger) OverridePrefix(showTimestamp, showCaller, showLevel bool) *Logger {
-  l.pushOverride(showTimestamp, showCaller, showLevel)
-  return l
+  return l.With(PrefixOptions{Timestamp: showTimestamp, Caller: showCaller, Level: showLevel})
 }
 
-// Global logger: OverridePrefix overrides current log prefix settings only for the next call of a log output function. 
+// Global logger: OverridePrefix returns a Logger that uses the specified prefix settings instead of the current log
+// prefix settings.
 //
-// Log output functions are: Log/Logf/Logln, Info/Infof/Infoln, Warn/Warnf/Warnln, Error/Errorf/Errorln and 
-// Critical/Criticalf/Criticalln. Returns the global Logger object to allow chaining function calls.
+// It allows to override prefix settings for a single call of a log output function by chaining function calls, e.g.
+// OverridePrefix(false, false, false).Infoln("Message"). The global Logger is not modified.
 //
-// Important: Multiple calls of this function are cumulative.
+// Deprecated: Use With instead.
 func OverridePrefix(showTimestamp, showCaller, showLevel bool) *Logger { return Global().OverridePrefix(showTimestamp, showCaller, showLevel) }
 
 
 // Log prints the LOG message if current verbosity level is set to LOG.
 func (l *Logger) Log(msg string) {
-  l.logf(l.getOutput(LOG), LOG, msg)
+  l.logf(LOG, "%s", msg)
 }
 
 // Global logger: Log prints the message if current verbosity level is set to LOG.
@@ -293,7 +508,7 @@ func Log(msg string) { Global().Log(msg) }
 
 // Info prints the message if current verbosity level is set to INFO or lower.
 func (l *Logger) Info(msg string) {
-  l.logf(l.getOutput(INFO), INFO, msg)
+  l.logf(INFO, "%s", msg)
 }
 
 // Global logger: Info prints the message if current verbosity level is set to INFO or lower.
@@ -301,7 +516,7 @@ func Info(msg string) { Global().Info(msg) }
 
 // Warn prints the message if current verbosity level is set to WARN or lower.
 func (l *Logger) Warn(msg string) {
-  l.logf(l.getOutput(WARN), WARN, msg)
+  l.logf(WARN, "%s", msg)
 }
 
 // Global logger: Warn prints the message if current verbosity level is set to WARN or lower.
@@ -309,24 +524,24 @@ func Warn(msg string) { Global().Warn(msg) }
 
 // Error prints the message if current verbosity level is set to ERROR or lower.
 func (l *Logger) Error(msg string) {
-  l.logf(l.getOutput(ERROR), ERROR, msg)
+  l.logf(ERROR, "%s", msg)
 }
 
 // Global logger: Error prints the message if current verbosity level is set to ERROR or lower.
 func Error(msg string) { Global().Error(msg) }
 
-// Critical invokes a panic with the specified message.
+// Critical prints the message and performs the critical action, which invokes a panic by default.
 func (l *Logger) Critical(msg string) {
-  l.logf(l.getOutput(CRITICAL), CRITICAL, msg)
+  l.logf(CRITICAL, "%s", msg)
 }
 
-// Global logger: Critical invokes a panic with the specified message.
+// Global logger: Critical prints the message and performs the critical action, which invokes a panic by default.
 func Critical(msg string) { Global().Critical(msg) }
 
 
 // Logf prints the formatted string if current verbosity level is set to LOG.
 func (l *Logger) Logf(format string, a ...interface{}) {
-  l.logf(l.getOutput(LOG), LOG, format, a...)
+  l.logf(LOG, format, a...)
 }
 
 // Global logger: Logf prints the formatted string if current verbosity level is set to LOG.
@@ -334,7 +549,7 @@ func Logf(format string, a ...interface{}) { Global().Logf(format, a...) }
 
 // Infof prints the formatted string if current verbosity level is set to INFO or lower.
 func (l *Logger) Infof(format string, a ...interface{}) {
-  l.logf(l.getOutput(INFO), INFO, format, a...)
+  l.logf(INFO, format, a...)
 }
 
 // Global logger: Infof prints the formatted string if current verbosity level is set to INFO or lower.
@@ -342,7 +557,7 @@ func Infof(format string, a ...interface{}) { Global().Infof(format, a...) }
 
 // Warnf prints the formatted string if current verbosity level is set to WARN or lower.
 func (l *Logger) Warnf(format string, a ...interface{}) {
-  l.logf(l.getOutput(WARN), WARN, format, a...)
+  l.logf(WARN, format, a...)
 }
 
 // Global logger: Warnf prints the formatted string if current verbosity level is set to WARN or lower.
@@ -350,24 +565,24 @@ func Warnf(format string, a ...interface{}) { Global().Warnf(format, a...) }
 
 // Errorf prints the formatted string if current verbosity level is set to ERROR or lower.
 func (l *Logger) Errorf(format string, a ...interface{}) {
-  l.logf(l.getOutput(ERROR), ERROR, format, a...)
+  l.logf(ERROR, format, a...)
 }
 
 // Global logger: Errorf prints the formatted string if current verbosity level is set to ERROR or lower.
 func Errorf(format string, a ...interface{}) { Global().Errorf(format, a...) }
 
-// Criticalf invokes a panic with the formatted string.
+// Criticalf prints the formatted string and performs the critical action, which invokes a panic by default.
 func (l *Logger) Criticalf(format string, a ...interface{}) {
-  l.logf(l.getOutput(CRITICAL), CRITICAL, format, a...)
+  l.logf(CRITICAL, format, a...)
 }
 
-// Global logger: Criticalf invokes a panic with the formatted string.
+// Global logger: Criticalf prints the formatted string and performs the critical action, which invokes a panic by default.
 func Criticalf(format string, a ...interface{}) { Global().Criticalf(format, a...) }
 
 
 // Logln prints the message and a newline if current verbosity is set to LOG.
 func (l *Logger) Logln(msg string) {
-  l.logf(l.getOutput(LOG), LOG, "%s\n", msg)
+  l.logf(LOG, "%s\n", msg)
 }
 
 // Global logger: Logln prints the message and a newline if current verbosity is set to LOG.
@@ -375,7 +590,7 @@ func Logln(msg string) { Global().Logln(msg) }
 
 // Infoln prints the message and a newline if current verbosity is set to INFO or lower.
 func (l *Logger) Infoln(msg string) {
-  l.logf(l.getOutput(INFO), INFO, "%s\n", msg)
+  l.logf(INFO, "%s\n", msg)
 }
 
 // Global logger: Infoln prints the message and a newline if current verbosity is set to INFO or lower.
@@ -383,7 +598,7 @@ func Infoln(msg string) { Global().Infoln(msg) }
 
 // Warnln prints the message and a newline if current verbosity is set to WARN or lower.
 func (l *Logger) Warnln(msg string) {
-  l.logf(l.getOutput(WARN), WARN, "%s\n", msg)
+  l.logf(WARN, "%s\n", msg)
 }
 
 // Global logger: Warnln prints the message and a newline if current verbosity is set to WARN or lower.
@@ -391,21 +606,138 @@ func Warnln(msg string) { Global().Warnln(msg) }
 
 // Errorln prints the message and a newline if current verbosity is set to ERROR or lower.
 func (l *Logger) Errorln(msg string) {
-  l.logf(l.getOutput(ERROR), ERROR, "%s\n", msg)
+  l.logf(ERROR, "%s\n", msg)
 }
 
 // Global logger: Errorln prints the message and a newline if current verbosity is set to ERROR or lower.
 func Errorln(msg string) { Global().Errorln(msg) }
 
-// Criticalln invokes a panic with the message and a newline.
+// Criticalln prints the message and a newline and performs the critical action, which invokes a panic by default.
 func (l *Logger) Criticalln(msg string) {
-  l.logf(l.getOutput(CRITICAL), CRITICAL, "%s\n", msg)
+  l.logf(CRITICAL, "%s\n", msg)
 }
 
-// Global logger: Criticalln invokes a panic with the message and a newline.
+// Global logger: Criticalln prints the message and a newline and performs the critical action, which invokes a panic
+// by default.
 func Criticalln(msg string) { Global().Criticalln(msg) }
 
 
+// Logw prints the message followed by the given key/value pairs and a newline if current verbosity is set to LOG.
+//
+// Fields are specified as alternating keys and values, such as Logw("msg", "user", 42, "ip", ip), or as Field objects.
+func (l *Logger) Logw(msg string, keysAndValues ...interface{}) {
+  l.logw(LOG, msg, keysAndValues)
+}
+
+// Global logger: Logw prints the message followed by the given key/value pairs and a newline if current verbosity is set to LOG.
+//
+// Fields are specified as alternating keys and values, such as Logw("msg", "user", 42, "ip", ip), or as Field objects.
+func Logw(msg string, keysAndValues ...interface{}) { Global().Logw(msg, keysAndValues...) }
+
+// Infow prints the message followed by the given key/value pairs and a newline if current verbosity is set to INFO or lower.
+//
+// Fields are specified as alternating keys and values, such as Infow("msg", "user", 42, "ip", ip), or as Field objects.
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+  l.logw(INFO, msg, keysAndValues)
+}
+
+// Global logger: Infow prints the message followed by the given key/value pairs and a newline if current verbosity is set to INFO or lower.
+//
+// Fields are specified as alternating keys and values, such as Infow("msg", "user", 42, "ip", ip), or as Field objects.
+func Infow(msg string, keysAndValues ...interface{}) { Global().Infow(msg, keysAndValues...) }
+
+// Warnw prints the message followed by the given key/value pairs and a newline if current verbosity is set to WARN or lower.
+//
+// Fields are specified as alternating keys and values, such as Warnw("msg", "user", 42, "ip", ip), or as Field objects.
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+  l.logw(WARN, msg, keysAndValues)
+}
+
+// Global logger: Warnw prints the message followed by the given key/value pairs and a newline if current verbosity is set to WARN or lower.
+//
+// Fields are specified as alternating keys and values, such as Warnw("msg", "user", 42, "ip", ip), or as Field objects.
+func Warnw(msg string, keysAndValues ...interface{}) { Global().Warnw(msg, keysAndValues...) }
+
+// Errorw prints the message followed by the given key/value pairs and a newline if current verbosity is set to ERROR or lower.
+//
+// Fields are specified as alternating keys and values, such as Errorw("msg", "user", 42, "ip", ip), or as Field objects.
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+  l.logw(ERROR, msg, keysAndValues)
+}
+
+// Global logger: Errorw prints the message followed by the given key/value pairs and a newline if current verbosity is set to ERROR or lower.
+//
+// Fields are specified as alternating keys and values, such as Errorw("msg", "user", 42, "ip", ip), or as Field objects.
+func Errorw(msg string, keysAndValues ...interface{}) { Global().Errorw(msg, keysAndValues...) }
+
+// Criticalw prints the message followed by the given key/value pairs and a newline and performs the critical action,
+// which invokes a panic by default.
+//
+// Fields are specified as alternating keys and values or as Field objects.
+func (l *Logger) Criticalw(msg string, keysAndValues ...interface{}) {
+  l.logw(CRITICAL, msg, keysAndValues)
+}
+
+// Global logger: Criticalw prints the message followed by the given key/value pairs and a newline and performs the
+// critical action, which invokes a panic by default.
+//
+// Fields are specified as alternating keys and values or as Field objects.
+func Criticalw(msg string, keysAndValues ...interface{}) { Global().Criticalw(msg, keysAndValues...) }
+
+
+// Print prints the message if current verbosity level is set to the given level or lower.
+//
+// Messages of CRITICAL or higher levels perform the critical action after they have been printed.
+func (l *Logger) Print(level Level, msg string) {
+  l.logf(level, "%s", msg)
+}
+
+// Global logger: Print prints the message if current verbosity level is set to the given level or lower.
+//
+// Messages of CRITICAL or higher levels perform the critical action after they have been printed.
+func Print(level Level, msg string) { Global().Print(level, msg) }
+
+// Printf prints the formatted string if current verbosity level is set to the given level or lower.
+//
+// Messages of CRITICAL or higher levels perform the critical action after they have been printed.
+func (l *Logger) Printf(level Level, format string, a ...interface{}) {
+  l.logf(level, format, a...)
+}
+
+// Global logger: Printf prints the formatted string if current verbosity level is set to the given level or lower.
+//
+// Messages of CRITICAL or higher levels perform the critical action after they have been printed.
+func Printf(level Level, format string, a ...interface{}) { Global().Printf(level, format, a...) }
+
+// Println prints the message and a newline if current verbosity level is set to the given level or lower.
+//
+// Messages of CRITICAL or higher levels perform the critical action after they have been printed.
+func (l *Logger) Println(level Level, msg string) {
+  l.logf(level, "%s\n", msg)
+}
+
+// Global logger: Println prints the message and a newline if current verbosity level is set to the given level or lower.
+//
+// Messages of CRITICAL or higher levels perform the critical action after they have been printed.
+func Println(level Level, msg string) { Global().Println(level, msg) }
+
+// Printw prints the message followed by the given key/value pairs and a newline if current verbosity level is set to
+// the given level or lower.
+//
+// Fields are specified as alternating keys and values or as Field objects. Messages of CRITICAL or higher levels
+// perform the critical action after they have been printed.
+func (l *Logger) Printw(level Level, msg string, keysAndValues ...interface{}) {
+  l.logw(level, msg, keysAndValues)
+}
+
+// Global logger: Printw prints the message followed by the given key/value pairs and a newline if current verbosity
+// level is set to the given level or lower.
+//
+// Fields are specified as alternating keys and values or as Field objects. Messages of CRITICAL or higher levels
+// perform the critical action after they have been printed.
+func Printw(level Level, msg string, keysAndValues ...interface{}) { Global().Printw(level, msg, keysAndValues...) }
+
+
 // LogProgressDot is a specialized version of the function LogProgress.
 //
 // It prints zero, one or more instances of "dot" (.) characters based on the given arguments if current
@@ -428,8 +760,7 @@ func LogProgressDot(cur, max, progressMax int) { Global().LogProgressDot(cur, ma
 func (l *Logger) LogProgress(cur, max, progressMax int, symbol string) {
   s := Progress(cur, max, progressMax, symbol)
   if len(s) > 0 {
-    l.pushOverride(false, false, false)
-    l.logf(l.getOutput(LOG), LOG, s)
+    l.With(PrefixOptions{}).logf(LOG, "%s", s)
   }
 }
 
@@ -464,8 +795,7 @@ func InfoProgressDot(cur, max, progressMax int) { Global().InfoProgressDot(cur,
 func (l *Logger) InfoProgress(cur, max, progressMax int, symbol string) {
   s := Progress(cur, max, progressMax, symbol)
   if len(s) > 0 {
-    l.pushOverride(false, false, false)
-    l.logf(l.getOutput(INFO), INFO, s)
+    l.With(PrefixOptions{}).logf(INFO, "%s", s)
   }
 }
 
@@ -500,8 +830,7 @@ func WarnProgressDot(cur, max, progressMax int) { Global().WarnProgressDot(cur,
 func (l *Logger) WarnProgress(cur, max, progressMax int, symbol string) {
   s := Progress(cur, max, progressMax, symbol)
   if len(s) > 0 {
-    l.pushOverride(false, false, false)
-    l.logf(l.getOutput(WARN), WARN, s)
+    l.With(PrefixOptions{}).logf(WARN, "%s", s)
   }
 }
 
@@ -536,8 +865,7 @@ func ErrorProgressDot(cur, max, progressMax int) { Global().ErrorProgressDot(cur
 func (l *Logger) ErrorProgress(cur, max, progressMax int, symbol string) {
   s := Progress(cur, max, progressMax, symbol)
   if len(s) > 0 {
-    l.pushOverride(false, false, false)
-    l.logf(l.getOutput(ERROR), ERROR, s)
+    l.With(PrefixOptions{}).logf(ERROR, "%s", s)
   }
 }
 
@@ -574,107 +902,211 @@ func Progress(cur, max, progressMax int, symbol string) string {
 
 
 // Used internally. Handles writing log messages.
-func (l *Logger) logf(w io.Writer, level int, format string, a ...interface{}) {
-  if level > CRITICAL { level = CRITICAL }
+func (l *Logger) logf(level Level, format string, a ...interface{}) {
+  l.logEntry(level, l.fields, format, a...)
+}
 
-  if level >= l.verbosity {
-    if level == CRITICAL {
-      panic(fmt.Sprintf(format, a...))
-    }
 
-    if w == nil { w = l.getOutput(level) }
-    prefix := l.getLogPrefix(level)
-    msg := fmt.Sprintf(format, a...)
-    _, err := fmt.Fprintf(w, "%s%s", prefix, msg)
-    if err != nil {
-      l.logf(os.Stderr, ERROR, "logging.Logf(): %v", err)
-    }
+// Used internally. Handles writing log messages with structured fields. Log entries are terminated by a newline.
+func (l *Logger) logw(level Level, msg string, keysAndValues []interface{}) {
+  l.logEntry(level, appendFields(l.fields, makeFields(keysAndValues)), "%s\n", msg)
+}
+
+
+// Used internally. Handles writing log entries.
+func (l *Logger) logEntry(level Level, fields Fields, format string, a ...interface{}) {
+  caller, enabled := l.filterEntry(level)
+  // filtered entries are only formatted if a critical action is performed for them
+  if !enabled && level < CRITICAL { return }
+  l.logFiltered(level, caller, enabled, fields, format, a...)
+}
+
+
+// Used internally. Returns whether a log entry of the given level passes verbosity filtering and sampling.
+// Returns the caller if it has been determined for filtering.
+func (l *Logger) filterEntry(level Level) (runtime.Frame, bool) {
+  var caller runtime.Frame
+  min, max := l.verbosityRange()
+  enabled := level >= min
+  if enabled && level < max {
+    // verbosity depends on the call site
+    caller = l.getCaller()
+    enabled = level >= l.verbosityAt(caller)
   }
+  if enabled { enabled = l.sample(level, &caller) }
+  return caller, enabled
+}
+
 
-  l.popOverride()
+// Used internally. Writes a log entry which has passed filterEntry, or performs the critical action for a filtered
+// CRITICAL or FATAL entry.
+func (l *Logger) logFiltered(level Level, caller runtime.Frame, enabled bool, fields Fields, format string, a ...interface{}) {
+  entry := Entry{Time: time.Now(), Level: level, Message: fmt.Sprintf(format, a...), Fields: fields, Caller: caller}
+  if enabled {
+    l.emit(&entry)
+  } else if level >= CRITICAL {
+    l.critical(&entry)
+  }
 }
 
 
-// Used internally. Returns the Writer object of the specified log level.
-func (l *Logger) getOutput(level int) io.Writer {
-  if level < LOG { level = LOG }
-  if level > CRITICAL { level = CRITICAL }
-  return l.output[level]
+// Used internally. Attaches a stack trace if required, writes the log entry and performs the critical action for
+// CRITICAL and FATAL entries.
+//
+// The entry must pass verbosity filtering. Caller information is only determined if it is not already defined.
+func (l *Logger) emit(entry *Entry) {
+  l.attachStack(entry)
+  l.write(entry)
+  if entry.Level >= CRITICAL { l.critical(entry) }
 }
 
 
-// Used internally. Returns a log prefix string.
-func (l *Logger) getLogPrefix(level int) string {
-  var prefix strings.Builder
-  if l.prefixTS {
-    t := time.Now()
-    prefix.WriteString(t.Format(l.fmtTimestamp))
-    prefix.WriteString(" ")
+// Used internally. Completes the log entry by the settings of the Logger, invokes the hooks and writes the entry
+// to the output channel.
+func (l *Logger) write(entry *Entry) {
+  l.complete(entry)
+  l.mutex.RLock()
+  hooks, sinks := l.hooks, l.sinks
+  l.mutex.RUnlock()
+  hooked := hooks.registered(entry.Level)
+  if !entry.Prefix.Caller && !hooked {
+    entry.Caller = runtime.Frame{}
+  } else if !entry.HasCaller() {
+    entry.Caller = l.getCaller()
   }
-  if (l.prefixCaller) {
-    pc := make([]uintptr, 16)
-    cnt := runtime.Callers(1, pc) // skip runtime.Callers from calling stack
-    if cnt > 0 {
-      // determine key string that should not be present in the name string of the calling function
-      f := runtime.FuncForPC(pc[0])
-      key := f.Name()
-      pos := strings.Index(key, ".(*Logger)")
-      if pos >= 0 {
-        key = key[:pos]
-      }
-      // first function not matching key is our prime candidate
-      for i := 1; i < cnt; i++ {
-        f := runtime.FuncForPC(pc[i])
-        name := f.Name()
-        if strings.Index(name, key) < 0 {
-          _, line := f.FileLine(pc[i])
-          prefix.WriteString(fmt.Sprintf("%s:%d ", name, line))
-          break
-        }
-      }
-    }
+
+  if hooked {
+    // hooks may modify the fields without affecting the fields bound to the Logger
+    entry.Fields = append(Fields(nil), entry.Fields...)
+    if !l.fireHooks(hooks, HOOK_BEFORE_WRITE, entry) { return }
   }
-  if l.prefixLevel {
-    prefix.WriteString(l.getLevelString(level))
-    prefix.WriteString(" ")
+  caller := entry.Caller
+  if !entry.Prefix.Caller { entry.Caller = runtime.Frame{} }
+  if len(sinks) > 0 {
+    l.writeSinks(sinks, entry)
+  } else if err := l.writeOutput(nil, nil, entry); err != nil {
+    l.writeFailed(err, entry)
   }
-  return prefix.String()
-}
-
-
-// Used internally. Returns a textual representation of the given log level.
-func (l *Logger) getLevelString(level int) string {
-  var s string
-  if level < LOG { level = LOG }
-  if level > CRITICAL { level = CRITICAL }
-  switch level {
-    case LOG:       s = "LOG "
-    case INFO:      s = "INFO"
-    case WARN:      s = "WARN"
-    case ERROR:     s = "ERRO"
-    case CRITICAL:  s = "CRIT"
-    default:        s = "    "
+  if hooked {
+    entry.Caller = caller
+    l.fireHooks(hooks, HOOK_AFTER_WRITE, entry)
   }
-  return s
 }
 
 
-// Used internally. Pushes given log prefix options to the stack.
-func (l *Logger) pushOverride(ts, caller, level bool) {
-  l.overrideStack = append(l.overrideStack, l.prefixTS, l.prefixCaller, l.prefixLevel)
-  l.prefixTS = ts
-  l.prefixCaller = caller
-  l.prefixLevel = level
+// Used internally. Writes the completed log entry to the given output channel, or to the output channel of its
+// log level if undefined. Uses the Formatter of the Logger if no Formatter is specified.
+func (l *Logger) writeOutput(w io.Writer, formatter Formatter, entry *Entry) error {
+  l.mutex.RLock()
+  if w == nil { w = l.getOutput(entry.Level) }
+  if formatter == nil { formatter = l.formatter }
+  collapseTimeout := l.collapseTimeout
+  colorMode, terminals := l.colorResolved, l.terminals
+  l.mutex.RUnlock()
+  entry.Color = useColor(colorMode, w, terminals)
+
+  var err error
+  if collapseTimeout > 0 {
+    l.writeMutex.Lock()
+    err = l.collapse(w, formatter, entry, collapseTimeout)
+    l.writeMutex.Unlock()
+  } else if ew, ok := w.(EntryWriter); ok {
+    l.writeMutex.Lock()
+    err = l.retry(func() error { return ew.WriteEntry(entry) })
+    l.writeMutex.Unlock()
+  } else {
+    var buf bytes.Buffer
+    err = formatter.Format(&buf, entry)
+    if err == nil {
+      // a single Write call prevents log entries from being interleaved
+      l.writeMutex.Lock()
+      err = l.retry(func() error {
+        _, err := writeLevel(w, entry.Level, buf.Bytes())
+        return err
+      })
+      l.writeMutex.Unlock()
+    }
+  }
+  return err
 }
 
 
-// Used internally. Restores most recent log prefix overrides. Does nothing if no overrides were stored.
-func (l *Logger) popOverride() {
-  if len(l.overrideStack) > 2 {
-    idx := len(l.overrideStack) - 3
-    l.prefixTS = l.overrideStack[idx]
-    l.prefixCaller = l.overrideStack[idx+1]
-    l.prefixLevel = l.overrideStack[idx+2]
-    l.overrideStack = l.overrideStack[:idx]
+// Used internally. Completes prefix settings, timestamp format and name of the log entry by the settings of the Logger.
+func (l *Logger) complete(entry *Entry) {
+  l.mutex.RLock()
+  entry.Prefix = PrefixOptions{Timestamp: l.prefixTS, Caller: l.prefixCaller, Level: l.prefixLevel}
+  entry.TimestampFormat = l.fmtTimestamp
+  entry.CallerFormat = l.callerFormat
+  l.mutex.RUnlock()
+  if l.prefix != nil { entry.Prefix = *l.prefix }
+  entry.Name = l.name
+}
+
+
+// Used internally. Writes the log entry to the Writer object in a single operation.
+func writeEntry(w io.Writer, formatter Formatter, entry *Entry) error {
+  if ew, ok := w.(EntryWriter); ok {
+    return ew.WriteEntry(entry)
   }
+  var buf bytes.Buffer
+  if err := formatter.Format(&buf, entry); err != nil { return err }
+  _, err := writeLevel(w, entry.Level, buf.Bytes())
+  return err
+}
+
+
+// Used internally. Writes data of the given log level to the Writer object.
+func writeLevel(w io.Writer, level Level, p []byte) (int, error) {
+  if lw, ok := w.(LevelWriter); ok {
+    return lw.WriteLevel(level, p)
+  }
+  return w.Write(p)
+}
+
+
+// Used internally. Flushes all Writer objects which provide a method "Flush() error".
+func flushWriters(writers []io.Writer) error {
+  var result error
+  for _, w := range writers {
+    if f, ok := w.(interface{ Flush() error }); ok {
+      if err := f.Flush(); err != nil && result == nil {
+        result = err
+      }
+    }
+  }
+  return result
+}
+
+
+// Used internally. Returns a shallow copy of the Logger which shares the same state.
+func (l *Logger) clone() *Logger {
+  child := *l
+  return &child
+}
+
+
+// Used internally. Returns the Writer object of the specified log level. The caller must hold the lock.
+func (l *Logger) getOutput(level Level) io.Writer {
+  if w, ok := l.output[level]; ok { return w }
+  return level.DefaultOutput()
+}
+
+
+// Used internally. Returns information about the function associated with the given program counter.
+func getFrame(pc uintptr) runtime.Frame {
+  if pc == 0 { return runtime.Frame{} }
+  frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+  return frame
+}
+
+
+// Used internally. Returns a textual representation of the given log level, padded to at least four characters.
+func getLevelString(level Level) string {
+  return fmt.Sprintf("%-4s", level.ShortName())
+}
+
+
+// Used internally. Returns the full name of the given log level in lower case.
+func getLevelName(level Level) string {
+  return strings.ToLower(level.Name())
 }
@@ -1,7 +1,10 @@
 package logging
 
 import (
+  "bytes"
   "fmt"
+  "strings"
+  "sync"
   "testing"
 )
 
@@ -22,3 +25,133 @@ func TestLogging1(t *testing.T) {
 
   l.Criticalln("This is a critical error.")
 }
+
+func TestConcurrentLogging(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetVerbosity(LOG)
+  for _, level := range []Level{LOG, INFO, WARN, ERROR} {
+    l.SetOutput(level, &buf)
+  }
+
+  const workers, count = 16, 200
+  var wg sync.WaitGroup
+  for i := 0; i < workers; i++ {
+    wg.Add(1)
+    go func(id int) {
+      defer wg.Done()
+      for j := 0; j < count; j++ {
+        switch j % 4 {
+          case 0: l.Logf("worker %d message %d\n", id, j)
+          case 1: l.Infof("worker %d message %d\n", id, j)
+          case 2: l.Warnf("worker %d message %d\n", id, j)
+          default: l.Errorf("worker %d message %d\n", id, j)
+        }
+      }
+    }(i)
+  }
+  wg.Wait()
+
+  lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+  if len(lines) != workers * count {
+    t.Fatalf("expected %d lines, got %d", workers * count, len(lines))
+  }
+  for _, line := range lines {
+    var id, n int
+    if _, err := fmt.Sscanf(line, "worker %d message %d", &id, &n); err != nil {
+      t.Fatalf("malformed line %q: %v", line, err)
+    }
+  }
+}
+
+func TestConcurrentSettings(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  levels := []Level{LOG, INFO, WARN, ERROR}
+  for _, level := range levels {
+    l.SetOutput(level, &buf)
+  }
+
+  var wg sync.WaitGroup
+  for i := 0; i < 8; i++ {
+    wg.Add(2)
+    go func(id int) {
+      defer wg.Done()
+      for j := 0; j < 100; j++ {
+        l.SetVerbosity(levels[j % len(levels)])
+        l.IncreaseVerbosity()
+        l.DecreaseVerbosity()
+        l.SetPrefixTimestamp(j % 2 == 0)
+        l.SetPrefixCaller(j % 3 == 0)
+        l.SetPrefixLevel(j % 5 == 0)
+        l.SetTimestampFormat(TS_FMT_DATETIME)
+        l.SetOutput(levels[j % len(levels)], &buf)
+      }
+    }(i)
+    go func(id int) {
+      defer wg.Done()
+      for j := 0; j < 100; j++ {
+        l.OverridePrefix(false, false, false).Warnln("override")
+        l.Errorf("worker %d message %d\n", id, j)
+        l.InfoProgressDot(j, 100, 10)
+        _ = l.GetVerbosity()
+        _ = l.GetOutput(WARN)
+        _ = l.GetPrefixTimestamp() || l.GetPrefixCaller() || l.GetPrefixLevel()
+        _ = l.GetTimestampFormat()
+      }
+    }(i)
+  }
+  wg.Wait()
+}
+
+func TestWith(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(LOG, &buf)
+
+  // filtered messages must not leak prefix overrides into subsequent calls
+  l.With(PrefixOptions{}).Logln("filtered")
+  l.Infoln("parent")
+  l.With(PrefixOptions{}).Infoln("derived")
+  l.OverridePrefix(false, false, false).Infoln("override")
+  l.InfoProgress(0, 1, 3, "#")
+  l.Infoln("")
+
+  expected := "INFO parent\nderived\noverride\n###INFO \n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+  if !l.GetPrefixLevel() || l.With(PrefixOptions{}).GetPrefixLevel() {
+    t.Error("prefix options of derived logger affect parent logger")
+  }
+}
+
+func TestChildLoggers(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  db := l.Named("db").WithFields("component", "storage")
+  pool := db.Named("pool").WithFields(Int("size", 4))
+
+  // settings of the parent are visible to child loggers
+  l.SetPrefixLevel(true)
+  l.SetVerbosity(LOG)
+  l.SetOutput(LOG, &buf)
+
+  l.Logln("root")
+  db.Logf("query took %dms\n", 12)
+  pool.Logw("connected", "host", "localhost")
+  db.Logln("unaffected")
+
+  expected := "LOG  root\n" +
+              "LOG  db: query took 12ms component=storage\n" +
+              "LOG  db.pool: connected component=storage size=4 host=localhost\n" +
+              "LOG  db: unaffected component=storage\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+  if pool.GetName() != "db.pool" {
+    t.Errorf("expected name %q, got %q", "db.pool", pool.GetName())
+  }
+}
@@ -0,0 +1,388 @@
+package logging
+// Contains the rotating file output channel.
+
+import (
+  "compress/gzip"
+  "fmt"
+  "io"
+  "os"
+  "path/filepath"
+  "sort"
+  "strings"
+  "sync"
+  "time"
+)
+
+// Available rotation schedules.
+const (
+  // Log files are not rotated on a time schedule.
+  ROTATE_NONE = iota
+  // Log files are rotated at the beginning of every hour.
+  ROTATE_HOURLY
+  // Log files are rotated at midnight (local time).
+  ROTATE_DAILY
+)
+
+// Used internally. Timestamp layout inserted into the names of rotated log files.
+const rotateTimeFormat = "2006-01-02T15-04-05.000"
+
+// Used internally. Returns the current time. Can be replaced for testing purposes.
+var timeNow = time.Now
+
+
+// RotatingFile is an output channel which writes to a log file that is rotated on a size limit or time schedule.
+//
+// Rotated log files are renamed by inserting the rotation time in front of the file extension, e.g.
+// "app.log" is renamed to "app-2018-06-03T12-00-00.000.log", and optionally compressed in the background.
+// Old log files are removed based on the number of backups and their age. Errors of background compression are
+// returned by Flush and Close. RotatingFile is safe for concurrent use by multiple goroutines and can be shared by
+// multiple log levels and Logger objects.
+type RotatingFile struct {
+  mutex         sync.Mutex
+  filename      string
+  maxSize       int64
+  schedule      int
+  maxBackups    int
+  maxAge        time.Duration
+  compress      bool
+  file          *os.File        // nil if closed or if the log file could not be reopened after a rotation
+  closed        bool
+  size          int64
+  nextRotation  time.Time
+  millMutex     sync.Mutex      // serializes background compression and removal of old log files
+  millGroup     sync.WaitGroup  // tracks running background operations
+  millErr       error           // most recent error of background operations
+}
+
+
+// NewRotatingFile opens the specified log file for appending and returns a new RotatingFile object.
+//
+// The file is created if it does not exist. By default log files are never rotated.
+func NewRotatingFile(filename string) (*RotatingFile, error) {
+  f := RotatingFile{filename: filename}
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  if err := f.open(); err != nil {
+    return nil, err
+  }
+  return &f, nil
+}
+
+
+// GetMaxSize returns the max. size of the log file in bytes. A value of 0 indicates no size limit.
+func (f *RotatingFile) GetMaxSize() int64 {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  return f.maxSize
+}
+
+// SetMaxSize defines the max. size of the log file in bytes. The log file is rotated before this size is exceeded.
+// Specify 0 to disable the size limit.
+func (f *RotatingFile) SetMaxSize(size int64) {
+  if size < 0 { size = 0 }
+  f.mutex.Lock()
+  f.maxSize = size
+  f.mutex.Unlock()
+}
+
+
+// GetSchedule returns the time schedule for rotating the log file.
+func (f *RotatingFile) GetSchedule() int {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  return f.schedule
+}
+
+// SetSchedule defines the time schedule for rotating the log file.
+//
+// Supported schedules: ROTATE_NONE, ROTATE_HOURLY and ROTATE_DAILY.
+func (f *RotatingFile) SetSchedule(schedule int) {
+  if schedule < ROTATE_NONE || schedule > ROTATE_DAILY { schedule = ROTATE_NONE }
+  f.mutex.Lock()
+  f.schedule = schedule
+  f.nextRotation = nextRotationTime(timeNow(), schedule)
+  f.mutex.Unlock()
+}
+
+
+// GetMaxBackups returns the max. number of rotated log files to keep. A value of 0 indicates no limit.
+func (f *RotatingFile) GetMaxBackups() int {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  return f.maxBackups
+}
+
+// SetMaxBackups defines the max. number of rotated log files to keep. Older log files are removed after rotation.
+// Specify 0 to keep all rotated log files.
+func (f *RotatingFile) SetMaxBackups(count int) {
+  if count < 0 { count = 0 }
+  f.mutex.Lock()
+  f.maxBackups = count
+  f.mutex.Unlock()
+}
+
+
+// GetMaxAge returns the max. age of rotated log files. A value of 0 indicates no limit.
+func (f *RotatingFile) GetMaxAge() time.Duration {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  return f.maxAge
+}
+
+// SetMaxAge defines the max. age of rotated log files, based on their rotation time. Older log files are removed
+// after rotation. Specify 0 to keep rotated log files regardless of their age.
+func (f *RotatingFile) SetMaxAge(age time.Duration) {
+  if age < 0 { age = 0 }
+  f.mutex.Lock()
+  f.maxAge = age
+  f.mutex.Unlock()
+}
+
+
+// GetCompress returns whether rotated log files are compressed by gzip.
+func (f *RotatingFile) GetCompress() bool {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  return f.compress
+}
+
+// SetCompress defines whether rotated log files should be compressed by gzip. Compression is performed in the
+// background. Compressed files are indicated by the additional file extension ".gz".
+func (f *RotatingFile) SetCompress(set bool) {
+  f.mutex.Lock()
+  f.compress = set
+  f.mutex.Unlock()
+}
+
+
+// Write writes the data to the log file. The log file is rotated first if the data would exceed the size limit
+// or the time schedule is due.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+
+  if err := f.reopen(); err != nil {
+    return 0, err
+  }
+  now := timeNow()
+  if (f.maxSize > 0 && f.size > 0 && f.size + int64(len(p)) > f.maxSize) ||
+     (f.schedule != ROTATE_NONE && !now.Before(f.nextRotation)) {
+    if err := f.rotate(now); err != nil {
+      return 0, err
+    }
+  }
+  n, err := f.file.Write(p)
+  f.size += int64(n)
+  return n, err
+}
+
+
+// Rotate rotates the log file immediately.
+func (f *RotatingFile) Rotate() error {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  if err := f.reopen(); err != nil {
+    return err
+  }
+  return f.rotate(timeNow())
+}
+
+
+// Sync commits the current content of the log file to stable storage.
+func (f *RotatingFile) Sync() error {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  if err := f.reopen(); err != nil {
+    return err
+  }
+  return f.file.Sync()
+}
+
+
+// Flush returns the most recent error of background compression of rotated log files, if any. Data is written to
+// the log file without buffering.
+func (f *RotatingFile) Flush() error {
+  f.mutex.Lock()
+  defer f.mutex.Unlock()
+  err := f.millErr
+  f.millErr = nil
+  return err
+}
+
+
+// Close closes the log file and waits for pending background operations to complete. Returns the most recent error
+// of background compression if closing the log file succeeds.
+func (f *RotatingFile) Close() error {
+  f.mutex.Lock()
+  var err error
+  if f.file != nil {
+    err = f.file.Close()
+    f.file = nil
+  }
+  f.closed = true
+  f.mutex.Unlock()
+  f.millGroup.Wait()
+  if err == nil { err = f.Flush() }
+  return err
+}
+
+
+// Used internally. Opens the log file for appending. The caller must hold the lock.
+func (f *RotatingFile) open() error {
+  file, err := os.OpenFile(f.filename, os.O_WRONLY | os.O_APPEND | os.O_CREATE, 0644)
+  if err != nil {
+    return err
+  }
+  info, err := file.Stat()
+  if err != nil {
+    file.Close()
+    return err
+  }
+  f.file = file
+  f.size = info.Size()
+  return nil
+}
+
+
+// Used internally. Returns ErrClosed if the RotatingFile has been closed. Reopens the log file if it could not be
+// reopened after a previous rotation. The caller must hold the lock.
+func (f *RotatingFile) reopen() error {
+  if f.closed { return os.ErrClosed }
+  if f.file == nil { return f.open() }
+  return nil
+}
+
+
+// Used internally. Renames the current log file, opens a new log file and starts removing or compressing old log files
+// in the background. The caller must hold the lock.
+func (f *RotatingFile) rotate(now time.Time) error {
+  if err := f.file.Close(); err != nil {
+    return err
+  }
+  f.file = nil
+  backup := backupName(f.filename, now)
+  renamed := true
+  if err := os.Rename(f.filename, backup); os.IsNotExist(err) {
+    // the log file has been removed, there is nothing to compress
+    renamed = false
+  } else if err != nil {
+    if openErr := f.open(); openErr != nil {
+      return fmt.Errorf("%v; reopening log file: %w", err, openErr)
+    }
+    return err
+  }
+  if err := f.open(); err != nil {
+    return err
+  }
+  f.nextRotation = nextRotationTime(now, f.schedule)
+
+  filename, compress, maxBackups, maxAge := f.filename, f.compress, f.maxBackups, f.maxAge
+  f.millGroup.Add(1)
+  go func() {
+    defer f.millGroup.Done()
+    f.millMutex.Lock()
+    defer f.millMutex.Unlock()
+    if compress && renamed {
+      if err := compressFile(backup); err != nil {
+        f.mutex.Lock()
+        f.millErr = fmt.Errorf("logging: compressing %s: %w", backup, err)
+        f.mutex.Unlock()
+      }
+    }
+    removeBackups(filename, now, maxBackups, maxAge)
+  }()
+  return nil
+}
+
+
+// Used internally. Returns a name for the rotated log file which does not exist yet.
+func backupName(filename string, t time.Time) string {
+  ext := filepath.Ext(filename)
+  base := strings.TrimSuffix(filename, ext)
+  for {
+    name := fmt.Sprintf("%s-%s%s", base, t.Format(rotateTimeFormat), ext)
+    if !fileExists(name) && !fileExists(name + ".gz") {
+      return name
+    }
+    // log files rotated in quick succession are distinguished by their timestamp
+    t = t.Add(time.Millisecond)
+  }
+}
+
+
+// Used internally. Returns whether the specified file exists.
+func fileExists(filename string) bool {
+  _, err := os.Lstat(filename)
+  return err == nil
+}
+
+
+// Used internally. Returns the next rotation time for the given schedule.
+func nextRotationTime(now time.Time, schedule int) time.Time {
+  switch schedule {
+    case ROTATE_HOURLY:
+      return time.Date(now.Year(), now.Month(), now.Day(), now.Hour() + 1, 0, 0, 0, now.Location())
+    case ROTATE_DAILY:
+      return time.Date(now.Year(), now.Month(), now.Day() + 1, 0, 0, 0, 0, now.Location())
+  }
+  return time.Time{}
+}
+
+
+// Used internally. Compresses the file by gzip and removes the original file.
+func compressFile(filename string) error {
+  src, err := os.Open(filename)
+  if err != nil {
+    return err
+  }
+  defer src.Close()
+  dst, err := os.OpenFile(filename + ".gz", os.O_WRONLY | os.O_CREATE | os.O_TRUNC, 0644)
+  if err != nil {
+    return err
+  }
+  zw := gzip.NewWriter(dst)
+  _, err = io.Copy(zw, src)
+  if err == nil { err = zw.Close() }
+  if err2 := dst.Close(); err == nil { err = err2 }
+  if err != nil {
+    os.Remove(filename + ".gz")
+    return err
+  }
+  src.Close()
+  return os.Remove(filename)
+}
+
+
+// Used internally. Removes rotated log files that exceed the max. number of backups or max. age.
+func removeBackups(filename string, now time.Time, maxBackups int, maxAge time.Duration) {
+  if maxBackups == 0 && maxAge == 0 { return }
+
+  type backup struct {
+    path  string
+    time  time.Time
+  }
+  ext := filepath.Ext(filename)
+  prefix := filepath.Base(strings.TrimSuffix(filename, ext)) + "-"
+  dir := filepath.Dir(filename)
+  entries, err := os.ReadDir(dir)
+  if err != nil { return }
+
+  var backups []backup
+  for _, entry := range entries {
+    name := entry.Name()
+    if entry.IsDir() || !strings.HasPrefix(name, prefix) { continue }
+    ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz")
+    if !strings.HasSuffix(ts, ext) { continue }
+    t, err := time.ParseInLocation(rotateTimeFormat, strings.TrimSuffix(ts, ext), now.Location())
+    if err != nil { continue }
+    backups = append(backups, backup{filepath.Join(dir, name), t})
+  }
+
+  // newest backups first
+  sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+  for i, b := range backups {
+    if (maxBackups > 0 && i >= maxBackups) || (maxAge > 0 && now.Sub(b.time) > maxAge) {
+      os.Remove(b.path)
+    }
+  }
+}
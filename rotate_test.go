@@ -0,0 +1,181 @@
+package logging
+
+import (
+  "compress/gzip"
+  "io"
+  "os"
+  "path/filepath"
+  "sort"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+)
+
+// Returns the sorted names of all files in the directory.
+func listFiles(t *testing.T, dir string) []string {
+  entries, err := os.ReadDir(dir)
+  if err != nil {
+    t.Fatal(err)
+  }
+  var names []string
+  for _, entry := range entries {
+    names = append(names, entry.Name())
+  }
+  sort.Strings(names)
+  return names
+}
+
+func TestRotatingFileSize(t *testing.T) {
+  dir := t.TempDir()
+  now := time.Date(2018, 6, 3, 12, 0, 0, 0, time.Local)
+  timeNow = func() time.Time { return now }
+  defer func() { timeNow = time.Now }()
+
+  f, err := NewRotatingFile(filepath.Join(dir, "app.log"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  f.SetMaxSize(11)
+  f.SetMaxBackups(2)
+  l := NewLogger()
+  l.SetOutput(INFO, f)
+  l.SetOutput(WARN, f)
+  for i := 0; i < 4; i++ {
+    l.Infoln("12345")
+    l.Warnln("abcd")    // fills the log file up to the size limit
+    now = now.Add(time.Second)
+  }
+  f.Close()
+
+  expected := []string{"app-2018-06-03T12-00-02.000.log", "app-2018-06-03T12-00-03.000.log", "app.log"}
+  if names := listFiles(t, dir); strings.Join(names, ",") != strings.Join(expected, ",") {
+    t.Errorf("expected files %v, got %v", expected, names)
+  }
+  data, _ := os.ReadFile(filepath.Join(dir, "app.log"))
+  if string(data) != "12345\nabcd\n" {
+    t.Errorf("unexpected content: %q", data)
+  }
+}
+
+func TestRotatingFileSchedule(t *testing.T) {
+  dir := t.TempDir()
+  now := time.Date(2018, 6, 3, 23, 59, 0, 0, time.Local)
+  timeNow = func() time.Time { return now }
+  defer func() { timeNow = time.Now }()
+
+  f, err := NewRotatingFile(filepath.Join(dir, "app.log"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  f.SetSchedule(ROTATE_DAILY)
+  f.SetCompress(true)
+  f.SetMaxAge(36 * time.Hour)
+  for day := 0; day < 3; day++ {
+    f.Write([]byte("entry\n"))
+    now = now.Add(24 * time.Hour)
+  }
+  f.Close()
+
+  // the first log file is empty and exceeds the max. age after the last rotation
+  expected := []string{"app-2018-06-04T23-59-00.000.log.gz", "app-2018-06-05T23-59-00.000.log.gz", "app.log"}
+  names := listFiles(t, dir)
+  if strings.Join(names, ",") != strings.Join(expected, ",") {
+    t.Fatalf("expected files %v, got %v", expected, names)
+  }
+  zf, err := os.Open(filepath.Join(dir, names[0]))
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer zf.Close()
+  zr, err := gzip.NewReader(zf)
+  if err != nil {
+    t.Fatal(err)
+  }
+  if data, _ := io.ReadAll(zr); string(data) != "entry\n" {
+    t.Errorf("unexpected content: %q", data)
+  }
+}
+
+func TestRotatingFileConcurrent(t *testing.T) {
+  dir := t.TempDir()
+  f, err := NewRotatingFile(filepath.Join(dir, "app.log"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  f.SetMaxSize(1000)
+  l1, l2 := NewLogger(), NewLogger()
+  for _, level := range []Level{WARN, ERROR} {
+    l1.SetOutput(level, f)
+    l2.SetOutput(level, f)
+  }
+
+  var wg sync.WaitGroup
+  for i := 0; i < 8; i++ {
+    wg.Add(1)
+    go func(l *Logger) {
+      defer wg.Done()
+      for j := 0; j < 100; j++ {
+        l.Warnln("concurrent message")
+        l.Errorln("concurrent message")
+      }
+    }([]*Logger{l1, l2}[i % 2])
+  }
+  wg.Wait()
+  f.Close()
+
+  total := 0
+  for _, name := range listFiles(t, dir) {
+    data, err := os.ReadFile(filepath.Join(dir, name))
+    if err != nil {
+      t.Fatal(err)
+    }
+    if len(data) > 1000 {
+      t.Errorf("size limit exceeded: %s", name)
+    }
+    for _, line := range strings.SplitAfter(string(data), "\n") {
+      if len(line) > 0 && line != "concurrent message\n" {
+        t.Errorf("malformed line in %s: %q", name, line)
+      }
+    }
+    total += strings.Count(string(data), "\n")
+  }
+  if total != 1600 {
+    t.Errorf("expected 1600 lines, got %d", total)
+  }
+}
+
+
+func TestRotatingFileErrors(t *testing.T) {
+  // the compressed file name exceeds the max. length of file names
+  dir := t.TempDir()
+  f, err := NewRotatingFile(filepath.Join(dir, strings.Repeat("a", 226) + ".log"))
+  if err != nil {
+    t.Fatal(err)
+  }
+  f.SetCompress(true)
+  f.Write([]byte("data\n"))
+  if err := f.Rotate(); err != nil {
+    t.Fatal(err)
+  }
+  f.millGroup.Wait()
+  if err := f.Flush(); err == nil || !strings.Contains(err.Error(), "compressing") {
+    t.Errorf("unexpected error: %v", err)
+  }
+  if err := f.Flush(); err != nil {
+    t.Errorf("error not cleared: %v", err)
+  }
+
+  // the log file is reopened if it could not be opened after a rotation
+  f.mutex.Lock()
+  f.file.Close()
+  f.file = nil
+  f.mutex.Unlock()
+  if _, err := f.Write([]byte("reopened\n")); err != nil {
+    t.Errorf("unexpected error: %v", err)
+  }
+  f.Close()
+  if _, err := f.Write([]byte("closed\n")); err != os.ErrClosed {
+    t.Errorf("unexpected error: %v", err)
+  }
+}
@@ -0,0 +1,255 @@
+package logging
+// Contains rate limiting and sampling of log entries.
+
+import (
+  "fmt"
+  "runtime"
+  "sync"
+  "time"
+)
+
+// SamplingOptions defines how many log entries of a log level are written. Entries exceeding the limits are suppressed.
+//
+// Limits are tracked separately for every call site, i.e. every location in the source code which calls a log
+// function. The number of suppressed entries of a call site is reported by a summary entry at the end of the
+// sampling interval, e.g. "suppressed 1234 similar messages".
+type SamplingOptions struct {
+  Interval    time.Duration // Length of the sampling interval. Defaults to one second.
+  First       int           // Number of entries per call site and interval which are always written. 0 disables sampling by count.
+  Thereafter  int           // After the first entries, only every Mth entry per call site and interval is written. 0 suppresses all further entries.
+  Rate        float64       // Max. number of entries per second and call site (token bucket). 0 disables the limit.
+  Burst       int           // Max. number of consecutive entries per call site allowed by Rate. Defaults to Rate, at least 1.
+  LevelRate   float64       // Max. number of entries per second of all call sites of the level (token bucket). 0 disables the limit.
+  LevelBurst  int           // Max. number of consecutive entries allowed by LevelRate. Defaults to LevelRate, at least 1.
+}
+
+// Used internally. Applies the sampling options of a single log level.
+type sampler struct {
+  mutex   sync.Mutex
+  options SamplingOptions
+  sites   map[uintptr]*sampleSite
+  bucket  tokenBucket   // shared by all call sites
+  now     func() time.Time  // clock of the sampler, can be replaced for testing purposes
+  stopped bool          // set when the sampler is replaced, pending summaries are discarded
+  idle    time.Duration // call sites without entries for this duration are removed
+  pruned  time.Time     // time of the most recent removal of idle call sites
+}
+
+// Used internally. The sampling state of a single call site.
+type sampleSite struct {
+  start       time.Time     // start of the current interval
+  last        time.Time     // time of the most recent entry
+  count       int           // number of entries in the current interval
+  bucket      tokenBucket
+  suppressed  int           // number of suppressed entries not yet reported
+  logger      *Logger       // Logger of the most recently suppressed entry
+  caller      runtime.Frame
+  timer       *time.Timer   // reports suppressed entries at the end of the interval
+}
+
+// Used internally. A token bucket rate limiter.
+type tokenBucket struct {
+  tokens  float64
+  last    time.Time
+}
+
+
+// GetSampling returns a copy of the sampling options of the given log level. Returns nil if sampling is disabled.
+func (l *Logger) GetSampling(level Level) *SamplingOptions {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  s, ok := l.sampling[level]
+  if !ok { return nil }
+  options := s.options
+  return &options
+}
+
+// Global logger: GetSampling returns a copy of the sampling options of the given log level.
+// Returns nil if sampling is disabled.
+func GetSampling(level Level) *SamplingOptions { return Global().GetSampling(level) }
+
+
+// SetSampling defines rate limits and sampling of log entries of the given log level. Specify nil to disable sampling.
+//
+// Sampling requires caller information, which is determined for every log entry of the level that passes verbosity
+// filtering. Critical actions are performed for suppressed CRITICAL and FATAL messages as well. Summaries of
+// suppressed entries which are pending when the options are replaced or disabled are discarded, call Flush before
+// to write them.
+func (l *Logger) SetSampling(level Level, options *SamplingOptions) {
+  var s *sampler
+  if options != nil {
+    s = &sampler{options: *options, sites: make(map[uintptr]*sampleSite), now: time.Now}
+    if s.options.Interval <= 0 { s.options.Interval = time.Second }
+    if s.options.Burst <= 0 { s.options.Burst = defaultBurst(s.options.Rate) }
+    if s.options.LevelBurst <= 0 { s.options.LevelBurst = defaultBurst(s.options.LevelRate) }
+    // a call site is idle once its interval has ended and its token bucket is refilled
+    s.idle = s.options.Interval
+    if s.options.Rate > 0 {
+      if refill := time.Duration(float64(s.options.Burst) / s.options.Rate * float64(time.Second)); refill > s.idle { s.idle = refill }
+    }
+  }
+  l.mutex.Lock()
+  old := l.sampling[level]
+  if s == nil {
+    delete(l.sampling, level)
+  } else {
+    if l.sampling == nil { l.sampling = make(map[Level]*sampler) }
+    l.sampling[level] = s
+  }
+  l.mutex.Unlock()
+  if old != nil { old.stop() }
+}
+
+// Global logger: SetSampling defines rate limits and sampling of log entries of the given log level.
+// Specify nil to disable sampling.
+//
+// Sampling requires caller information, which is determined for every log entry of the level that passes verbosity
+// filtering. Critical actions are performed for suppressed CRITICAL and FATAL messages as well. Summaries of
+// suppressed entries which are pending when the options are replaced or disabled are discarded, call Flush before
+// to write them.
+func SetSampling(level Level, options *SamplingOptions) { Global().SetSampling(level, options) }
+
+
+// Used internally. Returns whether a log entry of the given level and call site passes the sampling options.
+// Caller information is determined if it is not already defined.
+func (l *Logger) sample(level Level, caller *runtime.Frame) bool {
+  l.mutex.RLock()
+  s := l.sampling[level]
+  l.mutex.RUnlock()
+  if s == nil { return true }
+  if caller.PC == 0 { *caller = l.getCaller() }
+  return s.allow(l, level, *caller)
+}
+
+
+// Used internally. Writes summary entries for all call sites with suppressed log entries and stops their summary timers.
+func (l *Logger) flushSampling() {
+  l.mutex.RLock()
+  samplers := make(map[Level]*sampler, len(l.sampling))
+  for level, s := range l.sampling {
+    samplers[level] = s
+  }
+  l.mutex.RUnlock()
+  for level, s := range samplers {
+    s.mutex.Lock()
+    sites := make([]*sampleSite, 0, len(s.sites))
+    for _, site := range s.sites {
+      sites = append(sites, site)
+    }
+    s.mutex.Unlock()
+    for _, site := range sites {
+      s.report(level, site)
+    }
+  }
+}
+
+
+// Used internally. Applies the sampling options to the log entry of the given call site.
+func (s *sampler) allow(l *Logger, level Level, caller runtime.Frame) bool {
+  now := s.now()
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  if s.stopped { return true }
+  if now.Sub(s.pruned) >= s.options.Interval { s.prune(now) }
+  site, ok := s.sites[caller.PC]
+  if !ok {
+    site = &sampleSite{start: now, caller: caller}
+    s.sites[caller.PC] = site
+  }
+  if now.Sub(site.start) >= s.options.Interval {
+    site.start = now
+    site.count = 0
+  }
+  site.count++
+  site.last = now
+
+  allowed := true
+  if s.options.First > 0 && site.count > s.options.First {
+    allowed = s.options.Thereafter > 0 && (site.count - s.options.First) % s.options.Thereafter == 0
+  }
+  if allowed && s.options.Rate > 0 {
+    allowed = site.bucket.take(now, s.options.Rate, s.options.Burst)
+  }
+  if allowed && s.options.LevelRate > 0 {
+    allowed = s.bucket.take(now, s.options.LevelRate, s.options.LevelBurst)
+  }
+  if !allowed {
+    site.suppressed++
+    site.logger = l
+    if site.timer == nil {
+      site.timer = time.AfterFunc(site.start.Add(s.options.Interval).Sub(now), func() { s.report(level, site) })
+    }
+  }
+  return allowed
+}
+
+
+// Used internally. Writes a summary entry if log entries of the call site have been suppressed.
+func (s *sampler) report(level Level, site *sampleSite) {
+  s.mutex.Lock()
+  n, l := site.suppressed, site.logger
+  site.suppressed, site.logger = 0, nil
+  if site.timer != nil {
+    site.timer.Stop()
+    site.timer = nil
+  }
+  s.mutex.Unlock()
+  if n == 0 { return }
+
+  entry := Entry{
+    Time: s.now(),
+    Level: level,
+    Message: fmt.Sprintf("suppressed %d similar messages\n", n),
+    Fields: l.fields,
+    Caller: site.caller,
+  }
+  l.write(&entry)
+}
+
+
+// Used internally. Removes call sites which have been idle and have no pending summary. Must be called with the
+// mutex of the sampler locked.
+func (s *sampler) prune(now time.Time) {
+  for pc, site := range s.sites {
+    if site.timer == nil && site.suppressed == 0 && now.Sub(site.last) >= s.idle { delete(s.sites, pc) }
+  }
+  s.pruned = now
+}
+
+
+// Used internally. Stops the summary timers of all call sites and discards their suppressed entries.
+// Log entries passed to the sampler afterwards are not sampled.
+func (s *sampler) stop() {
+  s.mutex.Lock()
+  defer s.mutex.Unlock()
+  s.stopped = true
+  for _, site := range s.sites {
+    if site.timer != nil {
+      site.timer.Stop()
+      site.timer = nil
+    }
+    site.suppressed, site.logger = 0, nil
+  }
+}
+
+
+// Used internally. Removes a token from the bucket. Returns false if the bucket is empty.
+func (b *tokenBucket) take(now time.Time, rate float64, burst int) bool {
+  if b.last.IsZero() {
+    b.tokens = float64(burst)
+  } else if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+    b.tokens += elapsed * rate
+    if b.tokens > float64(burst) { b.tokens = float64(burst) }
+  }
+  b.last = now
+  if b.tokens < 1 { return false }
+  b.tokens--
+  return true
+}
+
+
+// Used internally. Returns the default burst size of the given rate.
+func defaultBurst(rate float64) int {
+  if rate < 1 { return 1 }
+  return int(rate)
+}
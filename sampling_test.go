@@ -0,0 +1,207 @@
+package logging
+
+import (
+  "bytes"
+  "strings"
+  "sync"
+  "testing"
+  "time"
+)
+
+// A Buffer which can be written and read concurrently.
+type syncBuffer struct {
+  mutex sync.Mutex
+  buf   bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+  b.mutex.Lock()
+  defer b.mutex.Unlock()
+  return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+  b.mutex.Lock()
+  defer b.mutex.Unlock()
+  return b.buf.String()
+}
+
+// Replaces the clock of the sampler of the given level.
+func setSamplingClock(l *Logger, level Level, now *time.Time) {
+  l.sampling[level].now = func() time.Time { return *now }
+}
+
+func TestSampling(t *testing.T) {
+  now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(WARN, &buf)
+  l.SetSampling(WARN, &SamplingOptions{Interval: time.Hour, First: 2, Thereafter: 3})
+  defer l.SetSampling(WARN, nil)
+  setSamplingClock(l, WARN, &now)
+  if options := l.GetSampling(WARN); options == nil || options.First != 2 || options.Burst != 1 {
+    t.Errorf("unexpected sampling options: %v", options)
+  }
+  for i := 1; i <= 10; i++ {
+    l.Warnf("message %d\n", i)
+  }
+  l.Flush()
+  expected := "message 1\nmessage 2\nmessage 5\nmessage 8\nsuppressed 6 similar messages\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  // new interval restarts counting
+  buf.Reset()
+  now = now.Add(time.Hour)
+  for i := 1; i <= 3; i++ {
+    l.WithFields("n", i).Warnf("message %d\n", i)
+  }
+  l.Flush()
+  l.Flush()
+  if expected := "message 1 n=1\nmessage 2 n=2\nsuppressed 1 similar messages n=3\n"; buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  l.SetSampling(WARN, nil)
+  if l.GetSampling(WARN) != nil {
+    t.Error("sampling not disabled")
+  }
+}
+
+
+func TestRateLimit(t *testing.T) {
+  now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  l.SetSampling(INFO, &SamplingOptions{Interval: time.Hour, Rate: 2, LevelRate: 3})
+  defer l.SetSampling(INFO, nil)
+  setSamplingClock(l, INFO, &now)
+  a := l.getCaller()
+  b := a
+  b.PC++
+  var result string
+  for i := 0; i < 5; i++ {
+    if l.sample(INFO, &a) { result += "a" }
+  }
+  now = now.Add(time.Second)  // refills 2 tokens per call site, 3 tokens per level
+  for i := 0; i < 5; i++ {
+    if l.sample(INFO, &a) { result += "a" }
+    if l.sample(INFO, &b) { result += "b" }
+  }
+  // call site "a" is limited to 2 entries per second, "b" is limited by the level
+  if result != "aaaba" {
+    t.Errorf("unexpected result: %q", result)
+  }
+  l.Flush()
+  if buf.String() != "suppressed 6 similar messages\nsuppressed 4 similar messages\n" &&
+     buf.String() != "suppressed 4 similar messages\nsuppressed 6 similar messages\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+
+func TestSamplingPrune(t *testing.T) {
+  now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+  l := NewLogger()
+  l.SetOutput(INFO, &bytes.Buffer{})
+  l.SetSampling(INFO, &SamplingOptions{Interval: time.Hour, First: 1})
+  defer l.SetSampling(INFO, nil)
+  setSamplingClock(l, INFO, &now)
+  s := l.sampling[INFO]
+  a := l.getCaller()
+  b := a
+  b.PC++
+  l.sample(INFO, &a)
+  l.sample(INFO, &b)
+  l.sample(INFO, &b)
+  // call site "a" is idle, "b" has a pending summary
+  now = now.Add(time.Hour)
+  l.sample(INFO, &b)
+  if _, ok := s.sites[a.PC]; ok || len(s.sites) != 1 {
+    t.Errorf("unexpected call sites: %v", s.sites)
+  }
+}
+
+
+func TestSamplingSummaryTimer(t *testing.T) {
+  var buf syncBuffer
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  l.SetSampling(ERROR, &SamplingOptions{Interval: 20 * time.Millisecond, First: 1})
+  defer l.SetSampling(ERROR, nil)
+  for i := 0; i < 4; i++ {
+    l.Errorln("flapping")
+  }
+  for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); {
+    if strings.Contains(buf.String(), "suppressed") { break }
+    time.Sleep(5 * time.Millisecond)
+  }
+  if buf.String() != "flapping\nsuppressed 3 similar messages\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+
+func TestSamplingCritical(t *testing.T) {
+  var buf bytes.Buffer
+  var calls int
+  l := NewLogger()
+  l.SetOutput(CRITICAL, &buf)
+  l.SetCriticalAction(CRITICAL_CALLBACK)
+  l.SetCriticalCallback(func(entry *Entry) { calls++ })
+  l.SetSampling(CRITICAL, &SamplingOptions{Interval: time.Hour, First: 1})
+  defer l.SetSampling(CRITICAL, nil)
+  for i := 0; i < 3; i++ {
+    l.Criticalln("down")
+  }
+  if buf.String() != "down\n" || calls != 3 {
+    t.Errorf("unexpected output %q with %d critical actions", buf.String(), calls)
+  }
+}
+
+
+func TestSamplingDisabled(t *testing.T) {
+  var buf syncBuffer
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  l.SetSampling(ERROR, &SamplingOptions{Interval: 10 * time.Millisecond, First: 1})
+  for i := 0; i < 3; i++ {
+    if i == 2 {
+      // the pending summary is discarded, further entries are not sampled
+      l.SetSampling(ERROR, nil)
+      time.Sleep(30 * time.Millisecond)
+    }
+    l.Errorln("flapping")
+  }
+  if buf.String() != "flapping\nflapping\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+
+// A value which counts how often it is formatted.
+type formatCounter int
+
+func (c *formatCounter) String() string {
+  *c++
+  return "counted"
+}
+
+func TestFilteredNotFormatted(t *testing.T) {
+  var buf bytes.Buffer
+  var c formatCounter
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  l.SetVerbosity(ERROR)
+  l.Infof("%v\n", &c)
+  l.SetSampling(ERROR, &SamplingOptions{Interval: time.Hour, First: 1})
+  defer l.SetSampling(ERROR, nil)
+  for i := 0; i < 3; i++ {
+    l.Errorf("%v\n", &c)
+  }
+  if c != 1 || buf.String() != "counted\n" {
+    t.Errorf("unexpected output %q, formatted %d times", buf.String(), c)
+  }
+}
@@ -0,0 +1,95 @@
+package logging
+// Contains definitions for writing log entries to multiple output channels.
+
+import (
+  "io"
+  "sync"
+)
+
+// Sink defines an output channel which receives log entries of a minimum level in its own layout.
+//
+// Sinks are written in order. Errors of a sink are handled by its error handler and do not prevent writing the
+// log entry to the other sinks. Use an AsyncWriter as Writer to prevent slow sinks from stalling log calls.
+type Sink struct {
+  Writer        io.Writer   // Output channel. Entries are written to the output channel of their log level if nil, see SetOutput.
+  Level         Level       // Minimum level of log entries written to the sink. Set to TRACE to receive all log entries.
+  Formatter     Formatter   // Layout of log entries. The Formatter of the Logger is used if nil.
+  ErrorHandler  func(err error, entry *Entry)  // Called if writing fails. The error handler of the Logger is used if nil.
+}
+
+
+// GetSinks returns a copy of the list of sinks. Returns an empty list if log entries are written to the output
+// channels of their log levels only.
+func (l *Logger) GetSinks() []Sink {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  return append([]Sink(nil), l.sinks...)
+}
+
+// Global logger: GetSinks returns a copy of the list of sinks. Returns an empty list if log entries are written to
+// the output channels of their log levels only.
+func GetSinks() []Sink { return Global().GetSinks() }
+
+
+// SetSinks replaces the list of sinks which receive the log entries.
+//
+// Log entries which pass verbosity filtering are written to every sink of the entry level or lower. A sink without
+// Writer writes to the output channel of the log level as defined by SetOutput. Specify no sinks to write log entries
+// to the output channels of their log levels only, which is the default. Example which prints text to the console
+// and writes all log entries as JSON to a file:
+//
+//   l.SetSinks(logging.Sink{Level: logging.TRACE}, logging.Sink{Writer: file, Level: logging.TRACE, Formatter: logging.NewJSONFormatter()})
+func (l *Logger) SetSinks(sinks ...Sink) {
+  list := append([]Sink(nil), sinks...)
+  l.mutex.Lock()
+  l.sinks = list
+  l.terminals = new(sync.Map)
+  l.mutex.Unlock()
+}
+
+// Global logger: SetSinks replaces the list of sinks which receive the log entries.
+//
+// Log entries which pass verbosity filtering are written to every sink of the entry level or lower. A sink without
+// Writer writes to the output channel of the log level as defined by SetOutput. Specify no sinks to write log entries
+// to the output channels of their log levels only, which is the default.
+func SetSinks(sinks ...Sink) { Global().SetSinks(sinks...) }
+
+
+// AddSink appends a sink to the list of sinks.
+//
+// If no sinks have been defined yet, the output channels of the log levels are added as first sink, so that they
+// continue to receive all log entries.
+func (l *Logger) AddSink(sink Sink) {
+  l.mutex.Lock()
+  defer l.mutex.Unlock()
+  list := make([]Sink, 0, len(l.sinks) + 2)
+  if len(l.sinks) == 0 {
+    list = append(list, Sink{Level: levelMin()})
+  }
+  l.sinks = append(append(list, l.sinks...), sink)
+  l.terminals = new(sync.Map)
+}
+
+// Global logger: AddSink appends a sink to the list of sinks.
+//
+// If no sinks have been defined yet, the output channels of the log levels are added as first sink, so that they
+// continue to receive all log entries.
+func AddSink(sink Sink) { Global().AddSink(sink) }
+
+
+// Used internally. Writes the log entry to all sinks of its level.
+func (l *Logger) writeSinks(sinks []Sink, entry *Entry) {
+  for i := range sinks {
+    sink := &sinks[i]
+    if entry.Level < sink.Level { continue }
+    // every sink may modify its own copy of the entry, e.g. strip colors
+    e := *entry
+    if err := l.writeOutput(sink.Writer, sink.Formatter, &e); err != nil {
+      if sink.ErrorHandler != nil {
+        sink.ErrorHandler(err, &e)
+      } else {
+        l.writeFailed(err, &e)
+      }
+    }
+  }
+}
@@ -0,0 +1,85 @@
+package logging
+
+import (
+  "bytes"
+  "errors"
+  "strings"
+  "testing"
+)
+
+// A Writer which always fails.
+type failWriter struct{}
+
+func (w failWriter) Write(p []byte) (int, error) {
+  return 0, errors.New("disk full")
+}
+
+func TestSinks(t *testing.T) {
+  var console, file, alerts bytes.Buffer
+  var failed []string
+  l := NewLogger()
+  l.SetVerbosity(LOG)
+  l.SetPrefixLevel(true)
+  l.SetColorMode(COLOR_ALWAYS)
+  l.SetOutput(LOG, &console)
+  l.SetOutput(INFO, &console)
+  l.SetOutput(ERROR, &console)
+
+  json := NewJSONFormatter()
+  json.TimeKey = KEY_OMIT
+  l.AddSink(Sink{Writer: failWriter{}, Level: ERROR, ErrorHandler: func(err error, entry *Entry) {
+    failed = append(failed, err.Error() + ": " + entry.Message)
+  }})
+  l.AddSink(Sink{Writer: &file, Level: INFO, Formatter: json})
+  l.AddSink(Sink{Writer: &alerts, Level: ERROR})
+  if sinks := l.GetSinks(); len(sinks) != 4 || sinks[0].Writer != nil || sinks[0].Level != TRACE {
+    t.Errorf("unexpected sinks: %v", sinks)
+  }
+
+  l.Logln("debug")
+  l.Infow("started", "port", 80)
+  l.Errorln("failed")
+
+  expected := COLOR_CYAN + "LOG " + COLOR_RESET + " debug\n" +
+              COLOR_GREEN + "INFO" + COLOR_RESET + " started port=80\n" +
+              COLOR_RED + "ERRO" + COLOR_RESET + " failed\n"
+  if console.String() != expected {
+    t.Errorf("expected %q, got %q", expected, console.String())
+  }
+  expected = `{"level":"info","msg":"started","port":80}` + "\n" + `{"level":"error","msg":"failed"}` + "\n"
+  if file.String() != expected {
+    t.Errorf("expected %q, got %q", expected, file.String())
+  }
+  if alerts.String() != COLOR_RED + "ERRO" + COLOR_RESET + " failed\n" {
+    t.Errorf("unexpected output: %q", alerts.String())
+  }
+  if len(failed) != 1 || failed[0] != "disk full: failed\n" {
+    t.Errorf("unexpected errors: %q", failed)
+  }
+
+  // failing sinks without error handler are reported to stderr
+  l.SetSinks(Sink{Writer: failWriter{}}, Sink{Writer: &file})
+  file.Reset()
+  stderr := captureStderr(t, func() { l.Infoln("next") })
+  if !strings.Contains(stderr, "disk full") || !strings.HasSuffix(file.String(), " next\n") {
+    t.Errorf("unexpected output %q, stderr %q", file.String(), stderr)
+  }
+  l.SetSinks()
+  if len(l.GetSinks()) != 0 {
+    t.Error("sinks not removed")
+  }
+}
+
+
+func TestSinksProgress(t *testing.T) {
+  var console, file bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &console)
+  l.AddSink(Sink{Writer: &file, Level: INFO})
+  for i := 0; i < 4; i++ {
+    l.InfoProgress(i, 4, 4, "#")
+  }
+  if console.String() != "####" || file.String() != "####" {
+    t.Errorf("unexpected output %q and %q", console.String(), file.String())
+  }
+}
@@ -0,0 +1,229 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+// Contains adapters for the structured logging package "log/slog".
+
+import (
+  "context"
+  "log/slog"
+  "strconv"
+  "strings"
+  "time"
+)
+
+// Slog level associated with CRITICAL messages. Slog records of this level or higher are logged as CRITICAL.
+const SLOG_LEVEL_CRITICAL = slog.LevelError + 4
+
+
+// SlogHandler is a slog.Handler which writes log records through a Logger.
+//
+// Slog levels are mapped to log levels: levels below slog.LevelDebug to TRACE, slog.LevelDebug to LOG, slog.LevelInfo
+// to INFO, slog.LevelInfo+2 to NOTICE, slog.LevelWarn to WARN, slog.LevelError to ERROR and SLOG_LEVEL_CRITICAL or
+// higher to CRITICAL. Attributes are added as fields to the log entry, attributes in groups are prefixed by the dotted
+// group names. Fields carried by the context of a log record are added as well, see FieldsFromContext. Verbosity, prefix
+// settings and output channels of the Logger apply to all log records.
+//
+// The critical action of the Logger is not performed for log records of level SLOG_LEVEL_CRITICAL or higher by
+// default, so that libraries which log through package "log/slog" cannot terminate the program. Use
+// WithCriticalAction to enable it. Like for the log functions of the Logger, the enabled critical action is performed
+// even if the record is filtered by verbosity or sampling.
+type SlogHandler struct {
+  logger    *Logger
+  group     string  // dotted group prefix of attribute keys
+  critical  bool    // whether the critical action is performed for CRITICAL records
+}
+
+
+// NewSlogHandler returns a new SlogHandler object which writes log records through the given Logger.
+func NewSlogHandler(l *Logger) *SlogHandler {
+  return &SlogHandler{logger: l}
+}
+
+// WithCriticalAction returns a SlogHandler which performs the critical action of the Logger for log records of level
+// SLOG_LEVEL_CRITICAL or higher if enabled is true, e.g. invokes a panic by default.
+func (h *SlogHandler) WithCriticalAction(enabled bool) *SlogHandler {
+  handler := *h
+  handler.critical = enabled
+  return &handler
+}
+
+// Enabled returns whether log records of the given level may pass the verbosity level of the Logger, or require
+// the critical action. Verbosity rules defined by SetVModule are applied by Handle.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+  min, _ := h.logger.verbosityRange()
+  return fromSlogLevel(level) >= min || h.critical && fromSlogLevel(level) >= CRITICAL
+}
+
+// Handle writes the log record through the Logger.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+  level := fromSlogLevel(r.Level)
+  caller := getFrame(r.PC)
+  enabled := level >= h.logger.verbosityAt(caller) && h.logger.sample(level, &caller)
+  if !enabled && (!h.critical || level < CRITICAL) { return nil }
+
+  fields := make(Fields, 0, r.NumAttrs())
+  r.Attrs(func(a slog.Attr) bool {
+    fields = appendSlogAttr(fields, h.group, a)
+    return true
+  })
+  t := r.Time
+  if t.IsZero() { t = time.Now() }
+  entry := Entry{
+    Time: t,
+    Level: level,
+    Message: r.Message + "\n",
+    Fields: appendFields(appendFields(h.logger.fields, FieldsFromContext(ctx)), fields),
+    Caller: caller,
+  }
+  if !enabled {
+    h.logger.critical(&entry)
+  } else if h.critical {
+    h.logger.emit(&entry)
+  } else {
+    h.logger.attachStack(&entry)
+    h.logger.write(&entry)
+  }
+  return nil
+}
+
+// WithAttrs returns a SlogHandler which adds the given attributes to all log records.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+  var fields Fields
+  for _, a := range attrs {
+    fields = appendSlogAttr(fields, h.group, a)
+  }
+  handler := *h
+  handler.logger = h.logger.WithFields(fields)
+  return &handler
+}
+
+// WithGroup returns a SlogHandler which adds the given group name to the keys of all subsequent attributes.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+  if len(name) == 0 { return h }
+  handler := *h
+  handler.group = h.group + name + "."
+  return &handler
+}
+
+
+// SlogWriter is an output channel which sends log entries to a slog.Handler.
+//
+// Log levels are mapped to slog levels: TRACE to slog.LevelDebug-4, LOG to slog.LevelDebug, INFO to slog.LevelInfo,
+// NOTICE to slog.LevelInfo+2, WARN to slog.LevelWarn, ERROR to slog.LevelError, CRITICAL to SLOG_LEVEL_CRITICAL and
+// FATAL to SLOG_LEVEL_CRITICAL+4. Custom levels are mapped to the slog level of the next lower predefined level.
+// Fields, the name of the Logger and the stack trace are added as attributes to the log records. The Formatter of the
+// Logger is not used.
+type SlogWriter struct {
+  handler slog.Handler
+}
+
+
+// NewSlogWriter returns a new SlogWriter object which sends log entries to the given slog.Handler.
+func NewSlogWriter(handler slog.Handler) *SlogWriter {
+  return &SlogWriter{handler: handler}
+}
+
+// NewSlogLogger returns a new Logger object which sends log entries of all log levels to the given slog.Handler.
+func NewSlogLogger(handler slog.Handler) *Logger {
+  l := NewLogger()
+  w := NewSlogWriter(handler)
+  for _, level := range Levels() {
+    l.SetOutput(level, w)
+  }
+  return l
+}
+
+// Write sends the data as a log record of level slog.LevelInfo.
+func (w *SlogWriter) Write(p []byte) (int, error) {
+  return w.WriteLevel(INFO, p)
+}
+
+// WriteLevel sends the data as a log record of the slog level associated with the given log level.
+func (w *SlogWriter) WriteLevel(level Level, p []byte) (int, error) {
+  entry := Entry{Time: time.Now(), Level: level, Message: string(p)}
+  if err := w.WriteEntry(&entry); err != nil {
+    return 0, err
+  }
+  return len(p), nil
+}
+
+// WriteEntry sends the log entry as a log record to the slog.Handler.
+func (w *SlogWriter) WriteEntry(entry *Entry) error {
+  ctx := context.Background()
+  level := toSlogLevel(entry.Level)
+  if !w.handler.Enabled(ctx, level) { return nil }
+
+  r := slog.NewRecord(entry.Time, level, strings.TrimSuffix(entry.Message, "\n"), entry.Caller.PC)
+  if len(entry.Name) > 0 {
+    r.AddAttrs(slog.String(KEY_NAME, entry.Name))
+  }
+  for _, f := range entry.Fields {
+    r.AddAttrs(slog.Any(f.Key, f.Value))
+  }
+  if len(entry.Stack) > 0 {
+    r.AddAttrs(slog.String(KEY_STACK, entry.Stack))
+  }
+  return w.handler.Handle(ctx, r)
+}
+
+
+// LogValue returns the error chain as slog group with the attributes "msg", "type", "stacktrace" and "causes".
+// The causes are a group of error chains with keys "0", "1", etc.
+func (c *ErrorChain) LogValue() slog.Value {
+  attrs := []slog.Attr{slog.String(KEY_MESSAGE, c.Message), slog.String("type", c.Type)}
+  if len(c.Stack) > 0 {
+    attrs = append(attrs, slog.String(KEY_STACK, c.Stack))
+  }
+  if len(c.Causes) > 0 {
+    causes := make([]slog.Attr, len(c.Causes))
+    for i, cause := range c.Causes {
+      causes[i] = slog.Any(strconv.Itoa(i), cause)
+    }
+    attrs = append(attrs, slog.Attr{Key: "causes", Value: slog.GroupValue(causes...)})
+  }
+  return slog.GroupValue(attrs...)
+}
+
+// Used internally. Adds the attribute to the fields. Attribute groups are flattened.
+func appendSlogAttr(fields Fields, group string, a slog.Attr) Fields {
+  a.Value = a.Value.Resolve()
+  if a.Equal(slog.Attr{}) { return fields }
+  if a.Value.Kind() == slog.KindGroup {
+    if len(a.Key) > 0 { group = group + a.Key + "." }
+    for _, ga := range a.Value.Group() {
+      fields = appendSlogAttr(fields, group, ga)
+    }
+    return fields
+  }
+  return append(fields, Field{Key: group + a.Key, Value: a.Value.Any()})
+}
+
+
+// Used internally. Returns the log level associated with the given slog level.
+func fromSlogLevel(level slog.Level) Level {
+  switch {
+    case level < slog.LevelDebug:       return TRACE
+    case level < slog.LevelInfo:        return LOG
+    case level < slog.LevelInfo + 2:    return INFO
+    case level < slog.LevelWarn:        return NOTICE
+    case level < slog.LevelError:       return WARN
+    case level < SLOG_LEVEL_CRITICAL:   return ERROR
+    default:                            return CRITICAL
+  }
+}
+
+
+// Used internally. Returns the slog level associated with the given log level.
+func toSlogLevel(level Level) slog.Level {
+  switch {
+    case level < LOG:       return slog.LevelDebug - 4
+    case level < INFO:      return slog.LevelDebug
+    case level < NOTICE:    return slog.LevelInfo
+    case level < WARN:      return slog.LevelInfo + 2
+    case level < ERROR:     return slog.LevelWarn
+    case level < CRITICAL:  return slog.LevelError
+    case level < FATAL:     return SLOG_LEVEL_CRITICAL
+    default:                return SLOG_LEVEL_CRITICAL + 4
+  }
+}
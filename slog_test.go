@@ -0,0 +1,103 @@
+//go:build go1.21
+// +build go1.21
+
+package logging
+
+import (
+  "bytes"
+  "context"
+  "errors"
+  "fmt"
+  "log/slog"
+  "strings"
+  "testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(WARN, &buf)
+  l.SetOutput(ERROR, &buf)
+
+  s := slog.New(NewSlogHandler(l.Named("http")))
+  s.Debug("filtered")
+  s.Info("request", "method", "GET", slog.Group("resp", "status", 200, slog.Group("", "bytes", 512)))
+  s.With("id", 7).WithGroup("db").Warn("slow query", "ms", 350)
+  s.Error("failed", slog.Group("empty"))
+
+  expected := "INFO http: request method=GET resp.status=200 resp.bytes=512\n" +
+              "WARN http: slow query id=7 db.ms=350\n" +
+              "ERRO http: failed\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  l.SetVerbosity(ERROR)
+  if s.Enabled(context.Background(), slog.LevelWarn) || !s.Enabled(context.Background(), slog.LevelError) {
+    t.Error("slog handler ignores verbosity level of the Logger")
+  }
+
+  buf.Reset()
+  l.SetOutput(LOG, &buf)
+  l.SetVModule("slog_test=LOG")
+  if !s.Enabled(context.Background(), slog.LevelDebug) {
+    t.Error("slog handler ignores vmodule rules")
+  }
+  s.Debug("enabled by rule")
+  if buf.String() != "LOG  http: enabled by rule\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+func TestSlogHandlerCritical(t *testing.T) {
+  var buf bytes.Buffer
+  var calls int
+  l := NewLogger()
+  l.SetOutput(CRITICAL, &buf)
+  l.SetCriticalCallback(func(entry *Entry) { calls++ })
+
+  // the default critical action CRITICAL_PANIC is not performed
+  slog.New(NewSlogHandler(l)).Log(context.Background(), SLOG_LEVEL_CRITICAL, "down")
+  l.SetCriticalAction(CRITICAL_CALLBACK)
+  slog.New(NewSlogHandler(l).WithCriticalAction(true)).WithGroup("g").Log(context.Background(), SLOG_LEVEL_CRITICAL, "down")
+  if buf.String() != "down\ndown\n" || calls != 1 {
+    t.Errorf("unexpected output %q with %d critical actions", buf.String(), calls)
+  }
+
+  // the critical action is performed regardless of verbosity
+  buf.Reset()
+  l.SetVerbosity(FATAL)
+  slog.New(NewSlogHandler(l)).Log(context.Background(), SLOG_LEVEL_CRITICAL, "filtered")
+  slog.New(NewSlogHandler(l).WithCriticalAction(true)).Log(context.Background(), SLOG_LEVEL_CRITICAL, "filtered")
+  if buf.Len() > 0 || calls != 2 {
+    t.Errorf("unexpected output %q with %d critical actions", buf.String(), calls)
+  }
+}
+
+
+func TestSlogWriter(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewSlogLogger(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+    ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+      if a.Key == slog.TimeKey { return slog.Attr{} }
+      return a
+    },
+  }))
+  l.Logln("filtered by verbosity")
+  l.Named("db").Infow("connected", "host", "localhost")
+  l.Warnf("%d retries\n", 3)
+  l.ErrorErr(fmt.Errorf("save failed: %w", errors.New("disk full")), "failed")
+
+  expected := "level=INFO msg=connected logger=db host=localhost\n" +
+              "level=WARN msg=\"3 retries\"\n" +
+              "level=ERROR msg=failed error.msg=\"save failed: disk full\" error.type=*fmt.wrapError " +
+              "error.causes.0.msg=\"disk full\" error.causes.0.type=*errors.errorString\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+  if strings.Contains(buf.String(), "filtered") {
+    t.Error("verbosity level of the Logger not applied")
+  }
+}
@@ -0,0 +1,164 @@
+package logging
+// Contains definitions for attaching stack traces to log entries.
+
+import (
+  "bytes"
+  "fmt"
+  "runtime"
+  "strings"
+)
+
+// Default max. number of stack frames of the logging goroutine in a stack trace.
+const STACK_DEPTH = 32
+
+// StackTraceOptions defines which log entries receive a stack trace of the goroutine which logged the entry.
+//
+// The stack trace starts at the caller of the log function. Frames of this package, of helper functions marked by
+// Helper, of the standard packages "log", "log/slog", "fmt", "io" and "bufio" in front of the caller, and of package
+// "runtime" are omitted.
+type StackTraceOptions struct {
+  Level         Level   // Minimum level of log entries with stack traces, e.g. ERROR.
+  MaxDepth      int     // Max. number of frames of the logging goroutine. Defaults to STACK_DEPTH.
+  AllGoroutines bool    // Whether stack traces of CRITICAL and FATAL entries include all other goroutines as well.
+  // Optional filter which is applied to the remaining frames of the logging goroutine. Return false to omit a frame.
+  Filter        func(frame runtime.Frame) bool
+}
+
+
+// GetStackTrace returns a copy of the stack trace options. Returns nil if stack traces are disabled.
+func (l *Logger) GetStackTrace() *StackTraceOptions {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  if l.stackTrace == nil { return nil }
+  options := *l.stackTrace
+  return &options
+}
+
+// Global logger: GetStackTrace returns a copy of the stack trace options. Returns nil if stack traces are disabled.
+func GetStackTrace() *StackTraceOptions { return Global().GetStackTrace() }
+
+
+// SetStackTrace attaches stack traces to log entries as defined by the options. Specify nil to disable stack traces,
+// which is the default.
+//
+// The stack trace is available as Entry.Stack. TextFormatter prints it below the log message, structured formatters
+// add it as element "stacktrace". Example which attaches stack traces to ERROR entries and above, and the stacks of
+// all goroutines to CRITICAL entries:
+//
+//   l.SetStackTrace(&logging.StackTraceOptions{Level: logging.ERROR, AllGoroutines: true})
+func (l *Logger) SetStackTrace(options *StackTraceOptions) {
+  var s *StackTraceOptions
+  if options != nil {
+    o := *options
+    if o.MaxDepth <= 0 { o.MaxDepth = STACK_DEPTH }
+    s = &o
+  }
+  l.mutex.Lock()
+  l.stackTrace = s
+  l.mutex.Unlock()
+}
+
+// Global logger: SetStackTrace attaches stack traces to log entries as defined by the options. Specify nil to
+// disable stack traces, which is the default.
+//
+// The stack trace is available as Entry.Stack. TextFormatter prints it below the log message, structured formatters
+// add it as element "stacktrace".
+func SetStackTrace(options *StackTraceOptions) { Global().SetStackTrace(options) }
+
+
+// Used internally. Attaches a stack trace to the log entry if required by the stack trace options.
+// Must be called by the goroutine which logs the entry.
+func (l *Logger) attachStack(entry *Entry) {
+  l.mutex.RLock()
+  options := l.stackTrace
+  l.mutex.RUnlock()
+  if options == nil || entry.Level < options.Level || len(entry.Stack) > 0 { return }
+
+  var sb strings.Builder
+  if options.AllGoroutines && entry.Level >= CRITICAL {
+    // the dump of all goroutines starts with the logging goroutine, whose frames are replaced by the filtered frames
+    header, others := splitGoroutines(allGoroutines())
+    sb.WriteString(header)
+    sb.WriteByte('\n')
+    l.writeStack(&sb, options)
+    if len(others) > 0 {
+      sb.WriteByte('\n')
+      sb.WriteString(others)
+    }
+  } else {
+    l.writeStack(&sb, options)
+  }
+  entry.Stack = sb.String()
+}
+
+
+// Used internally. Writes the filtered frames of the calling goroutine in the layout of runtime stack dumps.
+func (l *Logger) writeStack(sb *strings.Builder, options *StackTraceOptions) {
+  pc := make([]uintptr, options.MaxDepth + l.callerSkip + 32)
+  cnt := runtime.Callers(1, pc)
+  frames := runtime.CallersFrames(pc[:cnt])
+  leading, skip, depth := true, l.callerSkip, 0
+  for {
+    frame, more := frames.Next()
+    if leading && isLeadingFrame(frame) {
+      // frames in front of the caller
+    } else if leading && skip > 0 {
+      skip--
+    } else if funcPackage(frame.Function) != "runtime" && (options.Filter == nil || options.Filter(frame)) {
+      leading = false
+      if depth == options.MaxDepth {
+        sb.WriteString("...additional frames elided...\n")
+        return
+      }
+      writeFrame(sb, frame)
+      depth++
+    } else {
+      leading = false
+    }
+    if !more { break }
+  }
+  if cnt == len(pc) { sb.WriteString("...additional frames elided...\n") }
+}
+
+
+// Used internally. Writes the frames of the given program counters except for frames of package "runtime".
+func writeFrames(sb *strings.Builder, pc []uintptr) {
+  if len(pc) == 0 { return }
+  frames := runtime.CallersFrames(pc)
+  for {
+    frame, more := frames.Next()
+    if len(frame.Function) > 0 && funcPackage(frame.Function) != "runtime" { writeFrame(sb, frame) }
+    if !more { break }
+  }
+}
+
+
+// Used internally. Writes the stack frame in the layout of runtime stack dumps.
+func writeFrame(sb *strings.Builder, frame runtime.Frame) {
+  fmt.Fprintf(sb, "%s(...)\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+}
+
+
+// Used internally. Returns the stack traces of all goroutines.
+func allGoroutines() []byte {
+  buf := make([]byte, 64 << 10)
+  for {
+    n := runtime.Stack(buf, true)
+    if n < len(buf) { return buf[:n] }
+    buf = make([]byte, 2 * len(buf))
+  }
+}
+
+
+// Used internally. Splits a dump of all goroutines into the header line of the first goroutine and the remaining
+// goroutines.
+func splitGoroutines(dump []byte) (string, string) {
+  dump = bytes.TrimRight(dump, "\n")
+  first, others := dump, []byte(nil)
+  if pos := bytes.Index(dump, []byte("\n\n")); pos >= 0 {
+    first, others = dump[:pos], dump[pos+2:]
+  }
+  if pos := bytes.IndexByte(first, '\n'); pos >= 0 { first = first[:pos] }
+  if len(others) > 0 { others = append(others, '\n') }
+  return string(first), string(others)
+}
@@ -0,0 +1,117 @@
+package logging
+
+import (
+  "bytes"
+  "encoding/json"
+  "fmt"
+  "runtime"
+  "strings"
+  "testing"
+)
+
+func TestStackTrace(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(WARN, &buf)
+  l.SetOutput(ERROR, &buf)
+  l.SetStackTrace(&StackTraceOptions{Level: ERROR})
+  if options := l.GetStackTrace(); options == nil || options.Level != ERROR || options.MaxDepth != STACK_DEPTH {
+    t.Errorf("unexpected stack trace options: %v", options)
+  }
+
+  l.Warnln("no trace")
+  pc, file, line, _ := runtime.Caller(0)
+  l.Errorln("failed")
+  lines := strings.Split(buf.String(), "\n")
+  if lines[0] != "no trace" || lines[1] != "failed" {
+    t.Fatalf("unexpected output: %q", buf.String())
+  }
+  // the trace starts at the caller, frames of this package and the runtime are omitted
+  function := getFrame(pc).Function
+  if lines[2] != function + "(...)" || lines[3] != fmt.Sprintf("\t%s:%d", file, line + 1) {
+    t.Errorf("unexpected stack trace: %q", buf.String())
+  }
+  if strings.Contains(buf.String(), "runtime.") || strings.Contains(buf.String(), "emit") {
+    t.Errorf("stack trace contains filtered frames: %q", buf.String())
+  }
+
+  buf.Reset()
+  l.SetStackTrace(&StackTraceOptions{Level: ERROR, MaxDepth: 1})
+  _, _, line, _ = runtime.Caller(0)
+  l.Errorf("depth")
+  expected := fmt.Sprintf("depth\n%s(...)\n\t%s:%d\n...additional frames elided...\n", function, file, line + 1)
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+
+  buf.Reset()
+  l.SetStackTrace(&StackTraceOptions{Level: ERROR, Filter: func(frame runtime.Frame) bool { return false }})
+  l.Errorln("filtered")
+  if buf.String() != "filtered\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+
+  buf.Reset()
+  l.SetStackTrace(nil)
+  l.Errorln("disabled")
+  if buf.String() != "disabled\n" || l.GetStackTrace() != nil {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+
+func TestStackTraceStructured(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  l.SetFormatter(&JSONFormatter{TimeKey: KEY_OMIT})
+  l.SetStackTrace(&StackTraceOptions{Level: ERROR, MaxDepth: 1})
+  l.Errorw("failed", "id", 1)
+  var obj map[string]interface{}
+  if err := json.Unmarshal(buf.Bytes(), &obj); err != nil {
+    t.Fatalf("invalid JSON %q: %v", buf.String(), err)
+  }
+  if stack, _ := obj[KEY_STACK].(string); !strings.Contains(stack, "TestStackTraceStructured(...)\n") {
+    t.Errorf("unexpected stack trace: %q", buf.String())
+  }
+
+  buf.Reset()
+  l.SetFormatter(&LogfmtFormatter{TimeKey: KEY_OMIT, StackKey: "trace"})
+  l.Errorln("failed")
+  if !strings.HasPrefix(buf.String(), "level=error msg=failed trace=\"") || !strings.Contains(buf.String(), "\\n\\t") {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+
+func TestStackTraceAllGoroutines(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(ERROR, &buf)
+  l.SetOutput(CRITICAL, &buf)
+  l.SetCriticalAction(CRITICAL_NONE)
+  l.SetStackTrace(&StackTraceOptions{Level: ERROR, AllGoroutines: true})
+  done := make(chan struct{})
+  defer close(done)
+  started := make(chan struct{})
+  go func() {
+    close(started)
+    <-done
+  }()
+  <-started
+
+  l.Errorln("error")
+  if strings.Contains(buf.String(), "goroutine ") {
+    t.Errorf("ERROR entry contains all goroutines: %q", buf.String())
+  }
+  buf.Reset()
+  l.Criticalln("critical")
+  lines := strings.Split(buf.String(), "\n")
+  if lines[0] != "critical" || !strings.HasPrefix(lines[1], "goroutine ") || !strings.HasSuffix(lines[1], "[running]:") ||
+     !strings.Contains(lines[2], "TestStackTraceAllGoroutines(...)") {
+    t.Fatalf("unexpected output: %q", buf.String())
+  }
+  if strings.Count(buf.String(), "goroutine ") < 2 || strings.Contains(buf.String(), "attachStack") {
+    t.Errorf("unexpected stack traces: %q", buf.String())
+  }
+}
@@ -0,0 +1,117 @@
+package logging
+// Contains bridges for the standard library package "log" and other users of io.Writer.
+
+import (
+  "bytes"
+  "log"
+  "sync"
+)
+
+// Max. length of a line written by LineWriter. Longer lines are split into multiple log entries.
+const MAX_LINE_LENGTH = 64 << 10
+
+// LineWriter is a Writer object which splits incoming data into lines and writes each line as a log entry.
+// It is safe for concurrent use by multiple goroutines.
+type LineWriter struct {
+  mutex   sync.Mutex
+  logger  *Logger
+  level   Level
+  buf     []byte  // incomplete line
+}
+
+
+// Writer returns a Writer object which writes incoming data as log entries of the given level.
+//
+// Data is split into lines and each line is written as a separate log entry. Incomplete lines are buffered until the
+// line is completed or reaches MAX_LINE_LENGTH. The Writer can be passed to libraries which write diagnostic output
+// to an io.Writer. Verbosity level, prefix settings and output channels of the Logger apply to all log entries.
+// The caller of log entries is the function which called the standard packages "log", "fmt", "io" or "bufio".
+func (l *Logger) Writer(level Level) *LineWriter {
+  return &LineWriter{logger: l, level: level}
+}
+
+// Global logger: Writer returns a Writer object which writes incoming data as log entries of the given level.
+//
+// Data is split into lines and each line is written as a separate log entry. Incomplete lines are buffered until the
+// line is completed or reaches MAX_LINE_LENGTH. The Writer can be passed to libraries which write diagnostic output
+// to an io.Writer. Verbosity level, prefix settings and output channels of the global Logger apply to all log entries.
+// The caller of log entries is the function which called the standard packages "log", "fmt", "io" or "bufio".
+func Writer(level Level) *LineWriter { return Global().Writer(level) }
+
+
+// StdLogger returns a logger of the standard library package "log" which writes log entries of the given level.
+//
+// The returned logger does not add any prefixes on its own. Verbosity level, prefix settings and output channels
+// of the Logger apply to all log entries.
+func (l *Logger) StdLogger(level Level) *log.Logger {
+  return log.New(l.Writer(level), "", 0)
+}
+
+// Global logger: StdLogger returns a logger of the standard library package "log" which writes log entries of
+// the given level.
+//
+// The returned logger does not add any prefixes on its own. Verbosity level, prefix settings and output channels
+// of the global Logger apply to all log entries.
+func StdLogger(level Level) *log.Logger { return Global().StdLogger(level) }
+
+
+// RedirectStdLog redirects output of the standard logger of the package "log" to log entries of the given level.
+//
+// Prefix and flags of the standard logger are cleared, since the Logger adds prefixes on its own. Returns a function
+// which restores the previous output, prefix and flags of the standard logger.
+func (l *Logger) RedirectStdLog(level Level) func() {
+  flags, prefix, writer := log.Flags(), log.Prefix(), log.Writer()
+  log.SetFlags(0)
+  log.SetPrefix("")
+  log.SetOutput(l.Writer(level))
+  return func() {
+    log.SetFlags(flags)
+    log.SetPrefix(prefix)
+    log.SetOutput(writer)
+  }
+}
+
+// Global logger: RedirectStdLog redirects output of the standard logger of the package "log" to log entries of
+// the given level.
+//
+// Prefix and flags of the standard logger are cleared, since the Logger adds prefixes on its own. Returns a function
+// which restores the previous output, prefix and flags of the standard logger.
+func RedirectStdLog(level Level) func() { return Global().RedirectStdLog(level) }
+
+
+// Write writes every complete line of the data as a separate log entry. Line endings "\n" and "\r\n" are removed.
+// Always returns the length of the data.
+func (w *LineWriter) Write(p []byte) (int, error) {
+  w.mutex.Lock()
+  w.buf = append(w.buf, p...)
+  var lines [][]byte
+  for {
+    pos := bytes.IndexByte(w.buf, '\n')
+    if pos < 0 && len(w.buf) < MAX_LINE_LENGTH { break }
+    if pos < 0 || pos > MAX_LINE_LENGTH {
+      lines = append(lines, w.buf[:MAX_LINE_LENGTH])
+      w.buf = w.buf[MAX_LINE_LENGTH:]
+    } else {
+      lines = append(lines, bytes.TrimSuffix(w.buf[:pos], []byte{'\r'}))
+      w.buf = w.buf[pos+1:]
+    }
+  }
+  // further data is appended behind the split lines, they are written without holding the mutex
+  if len(w.buf) == 0 { w.buf = nil }
+  w.mutex.Unlock()
+  for _, line := range lines {
+    w.logger.logf(w.level, "%s\n", line)
+  }
+  return len(p), nil
+}
+
+
+// Flush writes a pending incomplete line as a log entry.
+func (w *LineWriter) Flush() error {
+  w.mutex.Lock()
+  line := w.buf
+  w.buf = nil
+  w.mutex.Unlock()
+  if len(line) > 0 { w.logger.logf(w.level, "%s\n", bytes.TrimSuffix(line, []byte{'\r'})) }
+  return nil
+}
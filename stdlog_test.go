@@ -0,0 +1,65 @@
+package logging
+
+import (
+  "bytes"
+  "fmt"
+  "log"
+  "runtime"
+  "strings"
+  "testing"
+)
+
+func TestStdLog(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixLevel(true)
+  l.SetOutput(INFO, &buf)
+  l.SetOutput(WARN, &buf)
+
+  w := l.Writer(WARN)
+  w.Write([]byte("first line\nsecond "))
+  w.Write([]byte("line\nincomplete"))
+  w.Flush()
+  l.StdLogger(INFO).Printf("from %s", "log.Logger")
+  l.Writer(LOG).Write([]byte("filtered\n"))
+
+  restore := l.RedirectStdLog(WARN)
+  log.Println("redirected")
+  restore()
+
+  expected := "WARN first line\nWARN second line\nWARN incomplete\nINFO from log.Logger\nWARN redirected\n"
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+}
+
+
+func TestStdLogLines(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetOutput(INFO, &buf)
+  w := l.Writer(INFO)
+  w.Write([]byte("crlf\r\n" + strings.Repeat("x", MAX_LINE_LENGTH + 1)))
+  w.Write([]byte("y\r"))
+  w.Flush()
+  expected := "crlf\n" + strings.Repeat("x", MAX_LINE_LENGTH) + "\nxy\n"
+  if buf.String() != expected {
+    t.Errorf("unexpected output: %.40q", buf.String())
+  }
+}
+
+
+func TestStdLogCaller(t *testing.T) {
+  var buf bytes.Buffer
+  l := NewLogger()
+  l.SetPrefixCaller(true)
+  l.SetCallerFormat(CALLER_SHORT_FILE)
+  l.SetOutput(INFO, &buf)
+  _, _, line, _ := runtime.Caller(0)
+  l.StdLogger(INFO).Println("log")
+  fmt.Fprintln(l.Writer(INFO), "fmt")
+  expected := fmt.Sprintf("stdlog_test.go:%d log\nstdlog_test.go:%d fmt\n", line + 1, line + 2)
+  if buf.String() != expected {
+    t.Errorf("expected %q, got %q", expected, buf.String())
+  }
+}
@@ -0,0 +1,286 @@
+package logging
+// Contains the syslog output channel.
+
+import (
+  "errors"
+  "fmt"
+  "net"
+  "os"
+  "path/filepath"
+  "strconv"
+  "strings"
+  "sync"
+  "time"
+)
+
+// Available syslog message formats.
+const (
+  // Messages are formatted as defined by RFC 5424.
+  SYSLOG_RFC5424 = iota
+  // Messages are formatted as defined by the legacy BSD syslog protocol (RFC 3164).
+  SYSLOG_RFC3164
+)
+
+// Available syslog facilities.
+const (
+  FACILITY_KERN = iota
+  FACILITY_USER
+  FACILITY_MAIL
+  FACILITY_DAEMON
+  FACILITY_AUTH
+  FACILITY_SYSLOG
+  FACILITY_LPR
+  FACILITY_NEWS
+  FACILITY_UUCP
+  FACILITY_CRON
+  FACILITY_AUTHPRIV
+  FACILITY_FTP
+  _
+  _
+  _
+  _
+  FACILITY_LOCAL0
+  FACILITY_LOCAL1
+  FACILITY_LOCAL2
+  FACILITY_LOCAL3
+  FACILITY_LOCAL4
+  FACILITY_LOCAL5
+  FACILITY_LOCAL6
+  FACILITY_LOCAL7
+)
+
+// Syslog severities as defined by RFC 5424.
+const (
+  SEVERITY_EMERG = iota
+  SEVERITY_ALERT
+  SEVERITY_CRIT
+  SEVERITY_ERR
+  SEVERITY_WARNING
+  SEVERITY_NOTICE
+  SEVERITY_INFO
+  SEVERITY_DEBUG
+)
+
+// Used internally. Well-known locations of the local syslog socket.
+var syslogSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+
+// SyslogWriter sends log entries to a syslog daemon.
+//
+// It can be specified as output channel for any log level. Log levels are mapped to syslog severities:
+// TRACE and LOG to debug, INFO to info, NOTICE to notice, WARN to warning, ERROR to err, CRITICAL to crit and FATAL
+// to alert. Custom levels are mapped to the severity of the next lower predefined level. Data written by calling
+// Write directly is sent with severity info. SyslogWriter is safe for concurrent use by multiple goroutines.
+type SyslogWriter struct {
+  mutex     sync.Mutex
+  network   string
+  address   string
+  facility  int
+  format    int
+  appName   string
+  hostname  string
+  procID    string
+  conn      net.Conn
+}
+
+
+// NewSyslogWriter returns a new SyslogWriter object which is connected to the specified syslog daemon.
+//
+// Supported networks are "unix", "unixgram", "udp" and "tcp" (and their IPv4/IPv6 variants). Specify an empty network
+// to connect to the local syslog daemon via unix socket, in which case address may be empty to probe well-known
+// socket locations, such as "/dev/log". Messages sent via TCP are framed by octet counting.
+// Facility is one of the FACILITY_xxx constants. Format is either SYSLOG_RFC5424 or SYSLOG_RFC3164.
+func NewSyslogWriter(network, address string, facility, format int) (*SyslogWriter, error) {
+  if facility < FACILITY_KERN || facility > FACILITY_LOCAL7 {
+    return nil, fmt.Errorf("logging: invalid syslog facility: %d", facility)
+  }
+  if format != SYSLOG_RFC5424 && format != SYSLOG_RFC3164 {
+    return nil, fmt.Errorf("logging: invalid syslog format: %d", format)
+  }
+  hostname, _ := os.Hostname()
+  w := SyslogWriter{
+    network: network,
+    address: address,
+    facility: facility,
+    format: format,
+    appName: filepath.Base(os.Args[0]),
+    hostname: hostname,
+    procID: strconv.Itoa(os.Getpid()),
+  }
+  if err := w.connect(); err != nil {
+    return nil, err
+  }
+  return &w, nil
+}
+
+
+// GetAppName returns the application name added to syslog messages.
+func (w *SyslogWriter) GetAppName() string {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.appName
+}
+
+// SetAppName defines the application name added to syslog messages. Defaults to the name of the executable.
+func (w *SyslogWriter) SetAppName(name string) {
+  w.mutex.Lock()
+  w.appName = name
+  w.mutex.Unlock()
+}
+
+
+// GetHostname returns the host name added to syslog messages.
+func (w *SyslogWriter) GetHostname() string {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.hostname
+}
+
+// SetHostname defines the host name added to syslog messages. Defaults to the host name reported by the system.
+func (w *SyslogWriter) SetHostname(name string) {
+  w.mutex.Lock()
+  w.hostname = name
+  w.mutex.Unlock()
+}
+
+
+// GetProcID returns the process id added to syslog messages.
+func (w *SyslogWriter) GetProcID() string {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  return w.procID
+}
+
+// SetProcID defines the process id added to syslog messages. Defaults to the id of the current process.
+func (w *SyslogWriter) SetProcID(id string) {
+  w.mutex.Lock()
+  w.procID = id
+  w.mutex.Unlock()
+}
+
+
+// Write sends the data as a syslog message with severity info.
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+  return w.WriteLevel(INFO, p)
+}
+
+
+// WriteLevel sends the data as a syslog message with the severity associated with the given log level.
+//
+// A trailing newline is removed from the message. The connection is reestablished once if sending fails.
+func (w *SyslogWriter) WriteLevel(level Level, p []byte) (int, error) {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+
+  t := time.Now()
+  text := strings.TrimSuffix(string(p), "\n")
+  var err error
+  for retry := 0; retry < 2; retry++ {
+    if w.conn == nil {
+      if err = w.connect(); err != nil { return 0, err }
+    }
+    if _, err = w.conn.Write(w.formatMessage(syslogSeverity(level), t, text)); err == nil {
+      return len(p), nil
+    }
+    w.conn.Close()
+    w.conn = nil
+  }
+  return 0, err
+}
+
+
+// Close closes the connection to the syslog daemon.
+func (w *SyslogWriter) Close() error {
+  w.mutex.Lock()
+  defer w.mutex.Unlock()
+  if w.conn == nil { return nil }
+  err := w.conn.Close()
+  w.conn = nil
+  return err
+}
+
+
+// Used internally. Connects to the syslog daemon. The caller must hold the lock.
+func (w *SyslogWriter) connect() error {
+  if len(w.network) > 0 {
+    conn, err := net.Dial(w.network, w.address)
+    if err != nil { return err }
+    w.conn = conn
+    return nil
+  }
+
+  addresses := syslogSockets
+  if len(w.address) > 0 { addresses = []string{w.address} }
+  for _, address := range addresses {
+    for _, network := range []string{"unixgram", "unix"} {
+      if conn, err := net.Dial(network, address); err == nil {
+        w.conn = conn
+        return nil
+      }
+    }
+  }
+  return errors.New("logging: unable to connect to local syslog daemon")
+}
+
+
+// Used internally. Returns the network type of the current connection. The caller must hold the lock.
+func (w *SyslogWriter) connNetwork() string {
+  if w.conn == nil { return "" }
+  return w.conn.LocalAddr().Network()
+}
+
+
+// Used internally. Returns a fully formatted and framed syslog message. The caller must hold the lock.
+func (w *SyslogWriter) formatMessage(severity int, t time.Time, msg string) []byte {
+  var sb strings.Builder
+  pri := w.facility * 8 + severity
+  switch w.format {
+    case SYSLOG_RFC3164:
+      // <PRI>TIMESTAMP HOSTNAME TAG[PID]: MSG
+      fmt.Fprintf(&sb, "<%d>%s %s %s", pri, t.Format(time.Stamp), syslogValue(w.hostname, 255), syslogValue(w.appName, 32))
+      if len(w.procID) > 0 { fmt.Fprintf(&sb, "[%s]", w.procID) }
+      sb.WriteString(": ")
+    default:
+      // <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+      fmt.Fprintf(&sb, "<%d>1 %s %s %s %s - - ", pri, t.Format("2006-01-02T15:04:05.000000Z07:00"),
+                  syslogValue(w.hostname, 255), syslogValue(w.appName, 48), syslogValue(w.procID, 128))
+  }
+  sb.WriteString(msg)
+
+  switch w.connNetwork() {
+    case "tcp", "tcp4", "tcp6":
+      // octet counting as defined by RFC 6587
+      return []byte(strconv.Itoa(sb.Len()) + " " + sb.String())
+    case "unix":
+      // local syslog daemons expect newline-terminated messages on stream sockets
+      sb.WriteByte('\n')
+  }
+  return []byte(sb.String())
+}
+
+
+// Used internally. Returns the header value, restricted to printable US-ASCII characters and the given length.
+// Returns the nil value "-" for empty strings.
+func syslogValue(s string, maxLen int) string {
+  s = strings.Map(func(r rune) rune {
+    if r <= ' ' || r > '~' { return '_' }
+    return r
+  }, s)
+  if len(s) > maxLen { s = s[:maxLen] }
+  if len(s) == 0 { s = "-" }
+  return s
+}
+
+
+// Used internally. Returns the syslog severity associated with the given log level.
+func syslogSeverity(level Level) int {
+  switch {
+    case level < INFO:      return SEVERITY_DEBUG
+    case level < NOTICE:    return SEVERITY_INFO
+    case level < WARN:      return SEVERITY_NOTICE
+    case level < ERROR:     return SEVERITY_WARNING
+    case level < CRITICAL:  return SEVERITY_ERR
+    case level < FATAL:     return SEVERITY_CRIT
+    default:                return SEVERITY_ALERT
+  }
+}
@@ -0,0 +1,122 @@
+package logging
+
+import (
+  "bufio"
+  "io"
+  "net"
+  "os"
+  "path/filepath"
+  "regexp"
+  "strconv"
+  "strings"
+  "testing"
+)
+
+func newTestSyslogWriter(t *testing.T, network, address string, format int) *SyslogWriter {
+  w, err := NewSyslogWriter(network, address, FACILITY_LOCAL3, format)
+  if err != nil {
+    t.Fatal(err)
+  }
+  w.SetAppName("myapp")
+  w.SetHostname("myhost")
+  w.SetProcID("123")
+  return w
+}
+
+func TestSyslogUDP(t *testing.T) {
+  conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer conn.Close()
+
+  w := newTestSyslogWriter(t, "udp", conn.LocalAddr().String(), SYSLOG_RFC5424)
+  defer w.Close()
+  l := NewLogger()
+  l.SetOutput(ERROR, w)
+  l.Errorln("disk full")
+
+  buf := make([]byte, 1024)
+  n, _, err := conn.ReadFrom(buf)
+  if err != nil {
+    t.Fatal(err)
+  }
+  // local3 (19) * 8 + err (3) = 155
+  re := regexp.MustCompile(`^<155>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}(Z|[+-]\d\d:\d\d) myhost myapp 123 - - disk full$`)
+  if !re.Match(buf[:n]) {
+    t.Errorf("unexpected message: %q", buf[:n])
+  }
+}
+
+func TestSyslogTCP(t *testing.T) {
+  ln, err := net.Listen("tcp", "127.0.0.1:0")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer ln.Close()
+
+  w := newTestSyslogWriter(t, "tcp", ln.Addr().String(), SYSLOG_RFC3164)
+  defer w.Close()
+  conn, err := ln.Accept()
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer conn.Close()
+
+  l := NewLogger()
+  l.SetVerbosity(LOG)
+  l.SetOutput(WARN, w)
+  l.SetOutput(LOG, w)
+  l.Warnln("low memory")
+  l.Logln("two\nlines")
+
+  r := bufio.NewReader(conn)
+  re := regexp.MustCompile(`^<(\d+)>[A-Z][a-z]{2} [ \d]\d \d\d:\d\d:\d\d myhost myapp\[123\]: (?s)(.*)$`)
+  for _, expected := range []struct { pri, msg string } {{"156", "low memory"}, {"159", "two\nlines"}} {
+    // octet counting: MSG-LEN SP SYSLOG-MSG
+    s, err := r.ReadString(' ')
+    if err != nil {
+      t.Fatal(err)
+    }
+    size, err := strconv.Atoi(strings.TrimSpace(s))
+    if err != nil {
+      t.Fatalf("invalid frame length %q", s)
+    }
+    msg := make([]byte, size)
+    if _, err := io.ReadFull(r, msg); err != nil {
+      t.Fatal(err)
+    }
+    m := re.FindSubmatch(msg)
+    if m == nil || string(m[1]) != expected.pri || string(m[2]) != expected.msg {
+      t.Errorf("unexpected message: %q", msg)
+    }
+  }
+}
+
+func TestSyslogUnix(t *testing.T) {
+  dir, err := os.MkdirTemp("", "logging")
+  if err != nil {
+    t.Fatal(err)
+  }
+  defer os.RemoveAll(dir)
+  path := filepath.Join(dir, "log")
+  conn, err := net.ListenPacket("unixgram", path)
+  if err != nil {
+    t.Skip("unix sockets not supported:", err)
+  }
+  defer conn.Close()
+
+  w := newTestSyslogWriter(t, "", path, SYSLOG_RFC5424)
+  defer w.Close()
+  w.Write([]byte("plain\n"))
+
+  buf := make([]byte, 1024)
+  n, _, err := conn.ReadFrom(buf)
+  if err != nil {
+    t.Fatal(err)
+  }
+  // local3 (19) * 8 + info (6) = 158
+  if !strings.HasPrefix(string(buf[:n]), "<158>1 ") || !strings.HasSuffix(string(buf[:n]), " myhost myapp 123 - - plain") {
+    t.Errorf("unexpected message: %q", buf[:n])
+  }
+}
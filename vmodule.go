@@ -0,0 +1,167 @@
+package logging
+// Contains per-package and per-file verbosity rules.
+
+import (
+  "fmt"
+  "path"
+  "runtime"
+  "strings"
+  "sync"
+)
+
+// Used internally. A verbosity rule for call sites matching a glob pattern.
+type vmoduleRule struct {
+  pattern   string
+  segments  int     // number of path segments of the pattern
+  level     Level
+}
+
+// Used internally. The verbosity rules of a Logger.
+type vmoduleRules struct {
+  spec    string
+  rules   []vmoduleRule
+  min     Level     // lowest level of all rules
+  max     Level     // highest level of all rules
+  cache   sync.Map  // maps program counters of call sites to matching rule index, or -1 if no rule matches
+}
+
+
+// GetVModule returns the current per-package and per-file verbosity rules in the format accepted by SetVModule.
+// Returns an empty string if no rules are defined.
+func (l *Logger) GetVModule() string {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  if l.vmodule == nil { return "" }
+  return l.vmodule.spec
+}
+
+// Global logger: GetVModule returns the current per-package and per-file verbosity rules in the format accepted
+// by SetVModule. Returns an empty string if no rules are defined.
+func GetVModule() string { return Global().GetVModule() }
+
+
+// SetVModule defines verbosity levels for log calls from specific packages or source files.
+//
+// The spec is a comma-separated list of "pattern=level" rules, e.g. "pkg/db/*=LOG,main=WARN". Levels are parsed by
+// ParseLevel. Patterns are glob patterns as used by path.Match. They are matched against the trailing path segments
+// of the source file with and without ".go" extension and of the package import path of the calling function.
+// The first matching rule replaces the verbosity level of the Logger for the call site. Decisions are cached per call
+// site. An empty spec removes all rules. The current rules are kept if the spec is invalid.
+func (l *Logger) SetVModule(spec string) error {
+  var rules *vmoduleRules
+  if spec = strings.TrimSpace(spec); len(spec) > 0 {
+    rules = &vmoduleRules{}
+    var specs []string
+    for _, s := range strings.Split(spec, ",") {
+      s = strings.TrimSpace(s)
+      if len(s) == 0 { continue }
+      pos := strings.LastIndex(s, "=")
+      if pos <= 0 { return fmt.Errorf("logging: invalid vmodule rule %q", s) }
+      pattern := strings.Trim(strings.TrimSpace(s[:pos]), "/")
+      if _, err := path.Match(pattern, ""); err != nil || len(pattern) == 0 {
+        return fmt.Errorf("logging: invalid vmodule pattern %q", s[:pos])
+      }
+      level, err := ParseLevel(s[pos+1:])
+      if err != nil { return err }
+      if len(rules.rules) == 0 || level < rules.min { rules.min = level }
+      if len(rules.rules) == 0 || level > rules.max { rules.max = level }
+      rules.rules = append(rules.rules, vmoduleRule{pattern: pattern, segments: strings.Count(pattern, "/") + 1, level: level})
+      specs = append(specs, pattern + "=" + level.String())
+    }
+    if len(specs) == 0 {
+      rules = nil
+    } else {
+      rules.spec = strings.Join(specs, ",")
+    }
+  }
+  l.mutex.Lock()
+  l.vmodule = rules
+  l.mutex.Unlock()
+  return nil
+}
+
+// Global logger: SetVModule defines verbosity levels for log calls from specific packages or source files.
+//
+// The spec is a comma-separated list of "pattern=level" rules, e.g. "pkg/db/*=LOG,main=WARN". Levels are parsed by
+// ParseLevel. Patterns are glob patterns as used by path.Match. They are matched against the trailing path segments
+// of the source file with and without ".go" extension and of the package import path of the calling function.
+// The first matching rule replaces the verbosity level of the Logger for the call site. Decisions are cached per call
+// site. An empty spec removes all rules. The current rules are kept if the spec is invalid.
+func SetVModule(spec string) error { return Global().SetVModule(spec) }
+
+
+// Used internally. Returns the lowest and highest verbosity level that may apply to any call site.
+// Log entries below the lowest level are discarded, log entries at or above the highest level are always logged.
+func (l *Logger) verbosityRange() (Level, Level) {
+  l.mutex.RLock()
+  defer l.mutex.RUnlock()
+  min, max := l.verbosity, l.verbosity
+  if l.vmodule != nil {
+    if l.vmodule.min < min { min = l.vmodule.min }
+    if l.vmodule.max > max { max = l.vmodule.max }
+  }
+  return min, max
+}
+
+
+// Used internally. Returns the verbosity level that applies to the given call site.
+func (l *Logger) verbosityAt(caller runtime.Frame) Level {
+  l.mutex.RLock()
+  verbosity, rules := l.verbosity, l.vmodule
+  l.mutex.RUnlock()
+  if rules == nil || caller.PC == 0 { return verbosity }
+
+  var index int
+  if v, ok := rules.cache.Load(caller.PC); ok {
+    index = v.(int)
+  } else {
+    index = rules.match(caller)
+    rules.cache.Store(caller.PC, index)
+  }
+  if index < 0 { return verbosity }
+  return rules.rules[index].level
+}
+
+
+// Used internally. Returns the index of the first rule matching the call site, or -1 if no rule matches.
+func (r *vmoduleRules) match(caller runtime.Frame) int {
+  file := strings.TrimSuffix(caller.File, ".go")
+  pkg := funcPackage(caller.Function)
+  for i, rule := range r.rules {
+    if matchSegments(rule, caller.File) || matchSegments(rule, file) || matchSegments(rule, pkg) {
+      return i
+    }
+  }
+  return -1
+}
+
+
+// Used internally. Returns whether the rule pattern matches the trailing path segments of the given path.
+func matchSegments(rule vmoduleRule, p string) bool {
+  if len(p) == 0 { return false }
+  pos := len(p)
+  for i := 0; i < rule.segments && pos >= 0; i++ {
+    pos = strings.LastIndex(p[:pos], "/")
+  }
+  ok, _ := path.Match(rule.pattern, p[pos+1:])
+  return ok
+}
+
+
+// Used internally. Returns the package import path of the fully qualified function name.
+//
+// Dots in the last segment of the import path are escaped as "%2e" in function names, e.g.
+// "gopkg.in/yaml%2ev3.Marshal", so the first dot after the last slash separates the package path from the function.
+func funcPackage(name string) string {
+  slash := strings.LastIndex(name, "/")
+  if pos := strings.Index(name[slash+1:], "."); pos >= 0 {
+    name = name[:slash+1+pos]
+  }
+  return unescapeFunction(name)
+}
+
+
+// Used internally. Returns the fully qualified function name with unescaped dots in the import path.
+func unescapeFunction(name string) string {
+  return strings.ReplaceAll(name, "%2e", ".")
+}
@@ -0,0 +1,98 @@
+package logging
+
+import (
+  "bytes"
+  "runtime"
+  "testing"
+)
+
+func TestVModule(t *testing.T) {
+  l := NewLogger()
+  if err := l.SetVModule(" pkg/db/*=log, vmodule_test = TRCE ,"); err != nil {
+    t.Fatal(err)
+  }
+  if spec := l.GetVModule(); spec != "pkg/db/*=LOG,vmodule_test=TRACE" {
+    t.Errorf("unexpected vmodule spec: %q", spec)
+  }
+  for _, spec := range []string{"main", "=INFO", "main=LOUD", "[=INFO"} {
+    if err := l.SetVModule(spec); err == nil {
+      t.Errorf("expected error for vmodule spec %q", spec)
+    }
+  }
+  if spec := l.GetVModule(); spec != "pkg/db/*=LOG,vmodule_test=TRACE" {
+    t.Errorf("vmodule spec modified by invalid spec: %q", spec)
+  }
+
+  pc, _, _, _ := runtime.Caller(0)
+  frame := getFrame(pc)
+  db := runtime.Frame{PC: 1, Function: "example.com/app/pkg/db.(*Conn).Query", File: "/src/app/pkg/db/conn.go"}
+  other := runtime.Frame{PC: 2, Function: "example.com/app/pkg/api.Serve", File: "/src/app/pkg/api/serve.go"}
+  tests := []struct {
+    frame     runtime.Frame
+    expected  Level
+  }{
+    {frame, TRACE},
+    {frame, TRACE},   // cached
+    {db, LOG},
+    {other, INFO},
+    {runtime.Frame{}, INFO},
+  }
+  for i, test := range tests {
+    if level := l.verbosityAt(test.frame); level != test.expected {
+      t.Errorf("test %d: expected verbosity %v, got %v", i, test.expected, level)
+    }
+  }
+
+  if err := l.SetVModule("app/pkg/api=ERROR,serve=LOG"); err != nil {
+    t.Fatal(err)
+  }
+  if level := l.verbosityAt(other); level != ERROR {
+    t.Errorf("expected verbosity ERROR for package rule, got %v", level)
+  }
+  if level := l.verbosityAt(db); level != INFO {
+    t.Errorf("expected verbosity INFO after changing rules, got %v", level)
+  }
+
+  var buf bytes.Buffer
+  l.SetOutput(LOG, &buf)
+  l.SetOutput(WARN, &buf)
+  l.SetVerbosity(WARN)
+  l.SetVModule("*=LOG")
+  l.Logln("enabled by rule")
+  l.SetVerbosity(INFO)
+  l.SetVModule("*=ERROR")
+  l.Warnln("disabled by rule")
+  l.SetVModule("")
+  l.Warnln("enabled by verbosity")
+  if buf.String() != "enabled by rule\nenabled by verbosity\n" {
+    t.Errorf("unexpected output: %q", buf.String())
+  }
+}
+
+
+func TestFuncPackage(t *testing.T) {
+  tests := []struct {
+    name      string
+    pkg       string
+  }{
+    {"main.main", "main"},
+    {"example.com/app/pkg/db.(*Conn).Query.func1", "example.com/app/pkg/db"},
+    {"gopkg.in/yaml%2ev3.(*Decoder).Decode", "gopkg.in/yaml.v3"},
+    {"gopkg.in/yaml%2ev3.Map[...]", "gopkg.in/yaml.v3"},
+  }
+  for _, test := range tests {
+    if pkg := funcPackage(test.name); pkg != test.pkg {
+      t.Errorf("%s: expected %q, got %q", test.name, test.pkg, pkg)
+    }
+  }
+
+  l := NewLogger()
+  l.SetVModule("yaml.v3=LOG")
+  frame := runtime.Frame{PC: 1, Function: "gopkg.in/yaml%2ev3.(*Decoder).Decode", File: "/src/yaml/decode.go", Line: 7}
+  if level := l.verbosityAt(frame); level != LOG {
+    t.Errorf("unexpected verbosity level: %s", level)
+  }
+  if s := formatCaller(frame, CALLER_SHORT_FUNCTION); s != "yaml.v3.(*Decoder).Decode:7" {
+    t.Errorf("unexpected caller: %q", s)
+  }
+}